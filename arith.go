@@ -0,0 +1,88 @@
+package iprange
+
+import (
+	"math/big"
+	"net/netip"
+
+	"github.com/gaissmai/iprange/internal/u128"
+)
+
+// AddrAt returns the i-th address of r, counting from zero at the first address.
+// It returns false if i is beyond the last address or r is invalid.
+func (r IPRange) AddrAt(i uint64) (netip.Addr, bool) {
+	return r.addrAt(u128.From64(i))
+}
+
+// AddrAtBig is like AddrAt, for indices of large IPv6 ranges.
+// It returns false if i is negative.
+func (r IPRange) AddrAtBig(i *big.Int) (netip.Addr, bool) {
+	u, ok := u128.FromBig(i)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	return r.addrAt(u)
+}
+
+// IndexOf returns the index of the address a in r, the reverse of AddrAt.
+// It returns false if a is not within r or the index exceeds uint64.
+func (r IPRange) IndexOf(a netip.Addr) (uint64, bool) {
+	u, ok := r.indexOf(a)
+	if !ok {
+		return 0, false
+	}
+	return u.Uint64()
+}
+
+// IndexOfBig is like IndexOf, for indices of large IPv6 ranges.
+func (r IPRange) IndexOfBig(a netip.Addr) (*big.Int, bool) {
+	u, ok := r.indexOf(a)
+	if !ok {
+		return nil, false
+	}
+	return u.Big(), true
+}
+
+// Offset returns the address n addresses after a, or before a if n is negative.
+// The address a must be within r. It returns false if the result
+// overflows past the last or underflows before the first address of r.
+func (r IPRange) Offset(a netip.Addr, n int64) (netip.Addr, bool) {
+	i, ok := r.indexOf(a)
+	if !ok {
+		return netip.Addr{}, false
+	}
+
+	var overflow bool
+	if n >= 0 {
+		i, overflow = i.Add(u128.From64(uint64(n)))
+	} else {
+		i, overflow = i.Sub(u128.From64(uint64(-(n + 1)) + 1))
+	}
+	if overflow {
+		return netip.Addr{}, false
+	}
+
+	return r.addrAt(i)
+}
+
+// addrAt returns the i-th address of r.
+func (r IPRange) addrAt(i u128.Uint128) (netip.Addr, bool) {
+	if r == zeroValue {
+		return netip.Addr{}, false
+	}
+
+	a, carry := u128.From(r.first).Add(i)
+	if carry || a.Compare(u128.From(r.last)) > 0 {
+		return netip.Addr{}, false
+	}
+	return a.Addr(r.first.Is4()), true
+}
+
+// indexOf returns the index of a in r.
+func (r IPRange) indexOf(a netip.Addr) (u128.Uint128, bool) {
+	if r == zeroValue || a.Zone() != "" || a.Less(r.first) || r.last.Less(a) {
+		return u128.Uint128{}, false
+	}
+
+	i, _ := u128.From(a).Sub(u128.From(r.first))
+	return i, true
+}
@@ -0,0 +1,155 @@
+package iprange_test
+
+import (
+	"math"
+	"math/big"
+	"net/netip"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestAddrAtIndexOf(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		r    string
+		i    uint64
+		want string
+	}{
+		{"10.0.0.0/24", 0, "10.0.0.0"},
+		{"10.0.0.0/24", 255, "10.0.0.255"},
+		{"10.0.0.3-10.0.17.134", 253, "10.0.1.0"},
+		{"0.0.0.0/0", math.MaxUint32, "255.255.255.255"},
+		{"2001:db8::/32", 1 << 40, "2001:db8::100:0:0"},
+		{"::/0", math.MaxUint64, "::ffff:ffff:ffff:ffff"},
+	}
+
+	for _, tt := range tests {
+		r := mustFromString(tt.r)
+		want := mustParseAddr(tt.want)
+
+		got, ok := r.AddrAt(tt.i)
+		if !ok || got != want {
+			t.Errorf("AddrAt(%s, %d), got %s %v, want %s", r, tt.i, got, ok, want)
+		}
+
+		got, ok = r.AddrAtBig(new(big.Int).SetUint64(tt.i))
+		if !ok || got != want {
+			t.Errorf("AddrAtBig(%s, %d), got %s %v, want %s", r, tt.i, got, ok, want)
+		}
+
+		idx, ok := r.IndexOf(want)
+		if !ok || idx != tt.i {
+			t.Errorf("IndexOf(%s, %s), got %d %v, want %d", r, want, idx, ok, tt.i)
+		}
+	}
+}
+
+func TestAddrAtBeyond(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		r string
+		i uint64
+	}{
+		{"10.0.0.0/24", 256},
+		{"255.255.255.255", 1},
+		{"0.0.0.0/0", math.MaxUint32 + 1},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff0/124", 16},
+	}
+
+	for _, tt := range tests {
+		if got, ok := mustFromString(tt.r).AddrAt(tt.i); ok {
+			t.Errorf("AddrAt(%s, %d), got %s, expected not ok", tt.r, tt.i, got)
+		}
+	}
+
+	if got, ok := (iprange.IPRange{}).AddrAt(0); ok {
+		t.Errorf("AddrAt(zero value), got %s, expected not ok", got)
+	}
+
+	if got, ok := mustFromString("10.0.0.0/8").AddrAtBig(big.NewInt(-1)); ok {
+		t.Errorf("AddrAtBig(-1), got %s, expected not ok", got)
+	}
+}
+
+func TestAddrAtBigIndexOfBig(t *testing.T) {
+	t.Parallel()
+	r := mustFromString("::/0")
+
+	// 2^127
+	i := new(big.Int).Lsh(big.NewInt(1), 127)
+	got, ok := r.AddrAtBig(i)
+	if want := mustParseAddr("8000::"); !ok || got != want {
+		t.Errorf("AddrAtBig(%s, %s), got %s %v, want %s", r, i, got, ok, want)
+	}
+
+	idx, ok := r.IndexOfBig(got)
+	if !ok || idx.Cmp(i) != 0 {
+		t.Errorf("IndexOfBig(%s, %s), got %s %v, want %s", r, got, idx, ok, i)
+	}
+
+	// beyond uint64
+	if _, ok := r.IndexOf(got); ok {
+		t.Errorf("IndexOf(%s, %s), expected not ok", r, got)
+	}
+
+	// 2^128, beyond the address space
+	i.Lsh(i, 1)
+	if got, ok := r.AddrAtBig(i); ok {
+		t.Errorf("AddrAtBig(%s, %s), got %s, expected not ok", r, i, got)
+	}
+}
+
+func TestIndexOfOutside(t *testing.T) {
+	t.Parallel()
+	r := mustFromString("10.0.0.0/24")
+
+	for _, a := range []netip.Addr{
+		mustParseAddr("9.255.255.255"),
+		mustParseAddr("10.0.1.0"),
+		mustParseAddr("::ffff:10.0.0.1"),
+		{},
+	} {
+		if idx, ok := r.IndexOf(a); ok {
+			t.Errorf("IndexOf(%s, %s), got %d, expected not ok", r, a, idx)
+		}
+		if idx, ok := r.IndexOfBig(a); ok {
+			t.Errorf("IndexOfBig(%s, %s), got %s, expected not ok", r, a, idx)
+		}
+	}
+}
+
+func TestOffset(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		r    string
+		a    string
+		n    int64
+		want string
+		ok   bool
+	}{
+		{"10.0.0.0/24", "10.0.0.10", 5, "10.0.0.15", true},
+		{"10.0.0.0/24", "10.0.0.10", -10, "10.0.0.0", true},
+		{"10.0.0.0/24", "10.0.0.10", 245, "10.0.0.255", true},
+		{"10.0.0.0/24", "10.0.0.10", 246, "", false},
+		{"10.0.0.0/24", "10.0.0.10", -11, "", false},
+		{"10.0.0.0/24", "10.0.1.10", 0, "", false},
+		{"10.0.0.0/24", "10.0.0.10", math.MinInt64, "", false},
+		{"0.0.0.0/0", "255.255.255.255", 1, "", false},
+		{"::/0", "::ffff:ffff:ffff:ffff", 1, "::1:0:0:0:0", true},
+		{"::/0", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", 1, "", false},
+		{"::/0", "::1", math.MinInt64, "", false},
+	}
+
+	for _, tt := range tests {
+		r := mustFromString(tt.r)
+		got, ok := r.Offset(mustParseAddr(tt.a), tt.n)
+		if ok != tt.ok {
+			t.Errorf("Offset(%s, %s, %d), got %s %v, want ok %v", r, tt.a, tt.n, got, ok, tt.ok)
+			continue
+		}
+		if ok && got != mustParseAddr(tt.want) {
+			t.Errorf("Offset(%s, %s, %d), got %s, want %s", r, tt.a, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package bogon generates bogon filter lists for IPv4 and IPv6.
+//
+// The bogons are the special-purpose blocks not globally reachable and the
+// multicast blocks, see iprange.Bogons. Given the records of the RIR
+// delegated statistics files, see package rir, the address space not
+// allocated or assigned by any RIR is added, the so-called fullbogons.
+//
+// The complement of the bogons is the routable address space.
+package bogon
+
+import (
+	"net/netip"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/rir"
+)
+
+var (
+	allIPv4 = mustFromString("0.0.0.0/0")
+	allIPv6 = mustFromString("::/0")
+)
+
+// Generate returns the merged bogon ranges and their complement, the
+// merged routable ranges, both sorted, IPv4 before IPv6.
+//
+// Without records, the bogons are the special-purpose and multicast blocks.
+// With records, the space not allocated or assigned in any record is added,
+// for each address family with at least one record. Use the records of the
+// statistics files of all five RIRs, a single file covers only the space
+// of its registry. Globally reachable special-purpose blocks like 2002::/16
+// (6to4) are never unallocated bogons, they are assigned by IANA itself.
+func Generate(recs []rir.Record) (bogons, routable []iprange.IPRange) {
+	bogons = iprange.Bogons()
+
+	// globally reachable special-purpose blocks count as allocated
+	var allocated []iprange.IPRange
+	for _, e := range iprange.SpecialPurposeRegistry() {
+		if e.GloballyReachable {
+			allocated = append(allocated, e.Range)
+		}
+	}
+
+	var has4, has6 bool
+	for _, rec := range recs {
+		if first, _ := rec.Range.Addrs(); first.Is4() {
+			has4 = true
+		} else {
+			has6 = true
+		}
+
+		if rec.Status == "allocated" || rec.Status == "assigned" {
+			allocated = append(allocated, rec.Range)
+		}
+	}
+
+	if has4 {
+		bogons = append(bogons, allIPv4.Remove(allocated)...)
+	}
+	if has6 {
+		bogons = append(bogons, allIPv6.Remove(allocated)...)
+	}
+	bogons = iprange.Merge(bogons)
+
+	routable = append(allIPv4.Remove(bogons), allIPv6.Remove(bogons)...)
+	return bogons, routable
+}
+
+// Prefixes returns the minimal prefixes covering the ranges,
+// as required by most filter formats.
+func Prefixes(rs []iprange.IPRange) []netip.Prefix {
+	var out []netip.Prefix
+	for _, r := range rs {
+		for p := range r.Prefixes() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
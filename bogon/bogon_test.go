@@ -0,0 +1,137 @@
+package bogon_test
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/bogon"
+	"github.com/gaissmai/iprange/rir"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func contains(rs []iprange.IPRange, s string) bool {
+	a := netip.MustParseAddr(s)
+	for _, r := range rs {
+		first, last := r.Addrs()
+		if first.Compare(a) <= 0 && a.Compare(last) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+const stats = `2|test|20240101|4|19830705|20240101|+0000
+test|*|ipv4|*|3|summary
+test|DE|ipv4|1.0.0.0|16777216|20100101|allocated
+test|US|ipv4|8.0.0.0|16777216|19921201|assigned
+test|ZZ|ipv4|9.0.0.0|16777216||available
+test|DE|ipv6|2a00::|12|20060101|allocated
+`
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	bogons, routable := bogon.Generate(nil)
+
+	if !slices.Equal(bogons, iprange.Bogons()) {
+		t.Errorf("Generate(nil), got bogons %v, want iprange.Bogons()", bogons)
+	}
+	if !slices.Equal(routable, iprange.Merge(routable)) {
+		t.Errorf("Generate(nil), routable not merged: %v", routable)
+	}
+	if !contains(routable, "9.9.9.9") || !contains(routable, "4000::1") {
+		t.Errorf("Generate(nil), routable misses unallocated space")
+	}
+}
+
+func TestGenerateFull(t *testing.T) {
+	t.Parallel()
+	recs, err := rir.NewReader(strings.NewReader(stats)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bogons, routable := bogon.Generate(recs)
+
+	// the allocated space and the globally reachable special-purpose blocks
+	var want []iprange.IPRange
+	for _, s := range []string{
+		"1.0.0.0/8",
+		"8.0.0.0/8",
+		"192.0.0.9-192.0.0.10",
+		"192.31.196.0/24",
+		"192.52.193.0/24",
+		"192.175.48.0/24",
+		"64:ff9b::/96",
+		"2001::/32",
+		"2001:1::1-2001:1::3",
+		"2001:3::/32",
+		"2001:4:112::/48",
+		"2001:20::/27",
+		"2002::/16",
+		"2620:4f:8000::/48",
+		"2a00::/12",
+	} {
+		want = append(want, mustFromString(s))
+	}
+	if !slices.Equal(routable, want) {
+		t.Errorf("Generate, got routable %v, want %v", routable, want)
+	}
+
+	// bogons and routable are the complement of each other
+	all := iprange.Merge(slices.Concat(bogons, routable))
+	if !slices.Equal(all, []iprange.IPRange{mustFromString("0.0.0.0/0"), mustFromString("::/0")}) {
+		t.Errorf("Generate, union of bogons and routable, got %v", all)
+	}
+	for _, r := range routable {
+		if rest := r.Remove(bogons); len(rest) != 1 || rest[0] != r {
+			t.Errorf("Generate, routable %v overlaps bogons", r)
+		}
+	}
+
+	if !contains(bogons, "9.9.9.9") {
+		t.Errorf("Generate, available space is no bogon")
+	}
+	if !contains(routable, "2002::1") || contains(bogons, "2002::1") {
+		t.Errorf("Generate, 6to4 2002::1 must stay routable")
+	}
+}
+
+func TestGenerateOneFamily(t *testing.T) {
+	t.Parallel()
+	recs := []rir.Record{{Range: mustFromString("1.0.0.0/8"), Status: "allocated"}}
+
+	_, routable := bogon.Generate(recs)
+
+	// IPv6 without records is not restricted
+	if !contains(routable, "2a00::1") || contains(routable, "8.8.8.8") {
+		t.Errorf("Generate, got routable %v", routable)
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	t.Parallel()
+	rs := []iprange.IPRange{
+		mustFromString("10.0.0.0-10.0.0.2"),
+		mustFromString("2001:db8::/32"),
+	}
+
+	got := bogon.Prefixes(rs)
+	want := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/31"),
+		netip.MustParsePrefix("10.0.0.2/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Prefixes(%v), got %v, want %v", rs, got, want)
+	}
+}
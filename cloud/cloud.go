@@ -0,0 +1,203 @@
+// Package cloud parses the published IP range documents of cloud providers
+// into IPRanges, for offline allowlist generation:
+//
+//   - AWS: https://ip-ranges.amazonaws.com/ip-ranges.json
+//   - GCP: https://www.gstatic.com/ipranges/cloud.json
+//   - Azure: the Service Tags JSON files, e.g. ServiceTags_Public_*.json
+//   - Cloudflare: https://www.cloudflare.com/ips-v4 and https://www.cloudflare.com/ips-v6
+//
+// The package does no network access, download the documents yourself.
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+
+	"github.com/gaissmai/iprange"
+)
+
+// Provider names as used in Entry.
+const (
+	AWS        = "aws"
+	GCP        = "gcp"
+	Azure      = "azure"
+	Cloudflare = "cloudflare"
+)
+
+// Entry is a single prefix of a provider document.
+type Entry struct {
+	Range    iprange.IPRange
+	Provider string
+	Service  string // e.g. "EC2", "Google Cloud", "AzureFrontDoor", empty if not given
+	Region   string // e.g. "eu-central-1", "europe-west3", "westeurope", empty if global
+}
+
+// ParseAWS parses the AWS ip-ranges.json document.
+// The service is the "service" field, the region the "region" field.
+func ParseAWS(r io.Reader) ([]Entry, error) {
+	var doc struct {
+		Prefixes []struct {
+			Prefix  string `json:"ip_prefix"`
+			Region  string `json:"region"`
+			Service string `json:"service"`
+		} `json:"prefixes"`
+		IPv6Prefixes []struct {
+			Prefix  string `json:"ipv6_prefix"`
+			Region  string `json:"region"`
+			Service string `json:"service"`
+		} `json:"ipv6_prefixes"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("aws: %w", err)
+	}
+
+	out := make([]Entry, 0, len(doc.Prefixes)+len(doc.IPv6Prefixes))
+	for _, p := range doc.Prefixes {
+		e, err := newEntry(AWS, p.Prefix, p.Service, p.Region)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	for _, p := range doc.IPv6Prefixes {
+		e, err := newEntry(AWS, p.Prefix, p.Service, p.Region)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+
+	return out, nil
+}
+
+// ParseGCP parses the GCP cloud.json document.
+// The service is the "service" field, the region the "scope" field.
+func ParseGCP(r io.Reader) ([]Entry, error) {
+	var doc struct {
+		Prefixes []struct {
+			IPv4Prefix string `json:"ipv4Prefix"`
+			IPv6Prefix string `json:"ipv6Prefix"`
+			Service    string `json:"service"`
+			Scope      string `json:"scope"`
+		} `json:"prefixes"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("gcp: %w", err)
+	}
+
+	out := make([]Entry, 0, len(doc.Prefixes))
+	for _, p := range doc.Prefixes {
+		e, err := newEntry(GCP, p.IPv4Prefix+p.IPv6Prefix, p.Service, p.Scope)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+
+	return out, nil
+}
+
+// ParseAzure parses an Azure Service Tags document.
+// The service is the "systemService" property, or the tag name without
+// region suffix if empty, e.g. "AzureCloud" for "AzureCloud.westeurope".
+// The region is the "region" property.
+func ParseAzure(r io.Reader) ([]Entry, error) {
+	var doc struct {
+		Values []struct {
+			Name       string `json:"name"`
+			Properties struct {
+				Region          string   `json:"region"`
+				SystemService   string   `json:"systemService"`
+				AddressPrefixes []string `json:"addressPrefixes"`
+			} `json:"properties"`
+		} `json:"values"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("azure: %w", err)
+	}
+
+	var out []Entry
+	for _, v := range doc.Values {
+		service := v.Properties.SystemService
+		if service == "" {
+			service, _, _ = strings.Cut(v.Name, ".")
+		}
+
+		for _, s := range v.Properties.AddressPrefixes {
+			e, err := newEntry(Azure, s, service, v.Properties.Region)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, e)
+		}
+	}
+
+	return out, nil
+}
+
+// ParseCloudflare parses a Cloudflare ips-v4 or ips-v6 list, one prefix per line.
+// The entries have neither service nor region.
+func ParseCloudflare(r io.Reader) ([]Entry, error) {
+	rs, err := iprange.ReadList(r)
+	if err != nil {
+		return nil, fmt.Errorf("cloudflare: %w", err)
+	}
+
+	out := make([]Entry, 0, len(rs))
+	for _, rng := range rs {
+		out = append(out, Entry{Range: rng, Provider: Cloudflare})
+	}
+	return out, nil
+}
+
+// Ranges returns the merged ranges of the entries selected by keep,
+// all entries if keep is nil.
+func Ranges(entries []Entry, keep func(Entry) bool) []iprange.IPRange {
+	var rs []iprange.IPRange
+	for _, e := range entries {
+		if keep == nil || keep(e) {
+			rs = append(rs, e.Range)
+		}
+	}
+	return iprange.Merge(rs)
+}
+
+// Match returns a filter for Ranges, selecting the entries with the
+// given service and region, compared case-insensitively.
+// An empty service or region matches all.
+func Match(service, region string) func(Entry) bool {
+	return func(e Entry) bool {
+		if service != "" && !strings.EqualFold(e.Service, service) {
+			return false
+		}
+		if region != "" && !strings.EqualFold(e.Region, region) {
+			return false
+		}
+		return true
+	}
+}
+
+func newEntry(provider, prefix, service, region string) (Entry, error) {
+	p, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", provider, err)
+	}
+
+	r, err := iprange.FromPrefix(p)
+	if err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", provider, err)
+	}
+
+	return Entry{
+		Range:    r,
+		Provider: provider,
+		Service:  service,
+		Region:   region,
+	}, nil
+}
@@ -0,0 +1,162 @@
+package cloud_test
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/cloud"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func mustParseFile(t *testing.T, name string, parse func(io.Reader) ([]cloud.Entry, error)) []cloud.Entry {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	entries, err := parse(f)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return entries
+}
+
+func rangesOf(ss ...string) []iprange.IPRange {
+	var out []iprange.IPRange
+	for _, s := range ss {
+		out = append(out, mustFromString(s))
+	}
+	return out
+}
+
+func TestAWS(t *testing.T) {
+	t.Parallel()
+	entries := mustParseFile(t, "aws-ip-ranges.json", cloud.ParseAWS)
+
+	if len(entries) != 8 {
+		t.Fatalf("ParseAWS, got %d entries, want 8", len(entries))
+	}
+	if e := entries[6]; e.Provider != cloud.AWS || e.Service != "EC2" || e.Region != "eu-central-1" || e.Range != mustFromString("2a05:d014::/36") {
+		t.Errorf("ParseAWS, got %+v", e)
+	}
+
+	tests := []struct {
+		service, region string
+		want            []iprange.IPRange
+	}{
+		{"ec2", "eu-central-1", rangesOf("18.192.0.0/14", "2a05:d014::/36")},
+		{"S3", "", rangesOf("3.5.140.0/22")},
+		{"", "us-west-2", rangesOf("52.94.76.0/22", "2600:1f14::/35")},
+		{"EC2", "us-west-2", nil},
+	}
+
+	for _, tt := range tests {
+		got := cloud.Ranges(entries, cloud.Match(tt.service, tt.region))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Ranges(%q, %q), got %v, want %v", tt.service, tt.region, got, tt.want)
+		}
+	}
+
+	if got := cloud.Ranges(entries, nil); len(got) != 5 {
+		t.Errorf("Ranges(nil), got %v, want 5 ranges", got)
+	}
+}
+
+func TestGCP(t *testing.T) {
+	t.Parallel()
+	entries := mustParseFile(t, "gcp-cloud.json", cloud.ParseGCP)
+
+	if len(entries) != 4 {
+		t.Fatalf("ParseGCP, got %d entries, want 4", len(entries))
+	}
+	if e := entries[1]; e.Provider != cloud.GCP || e.Service != "Google Cloud" || e.Region != "africa-south1" || e.Range != mustFromString("2600:1900:8000::/44") {
+		t.Errorf("ParseGCP, got %+v", e)
+	}
+
+	got := cloud.Ranges(entries, cloud.Match("", "europe-west3"))
+	if want := rangesOf("34.89.0.0/16"); !slices.Equal(got, want) {
+		t.Errorf("Ranges(europe-west3), got %v, want %v", got, want)
+	}
+}
+
+func TestAzure(t *testing.T) {
+	t.Parallel()
+	entries := mustParseFile(t, "azure-servicetags.json", cloud.ParseAzure)
+
+	if len(entries) != 6 {
+		t.Fatalf("ParseAzure, got %d entries, want 6", len(entries))
+	}
+
+	tests := []struct {
+		service, region string
+		want            []iprange.IPRange
+	}{
+		{"AzureCloud", "westeurope", rangesOf("13.69.0.0/16", "2603:1020:200::/46")},
+		{"ActionGroup", "", rangesOf("4.145.74.52/30", "2603:1000:4::/48")},
+		{"AzureFrontDoor", "", rangesOf("13.73.248.8/29")},
+	}
+
+	for _, tt := range tests {
+		got := cloud.Ranges(entries, cloud.Match(tt.service, tt.region))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Ranges(%q, %q), got %v, want %v", tt.service, tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestCloudflare(t *testing.T) {
+	t.Parallel()
+	v4 := mustParseFile(t, "cloudflare-ips-v4.txt", cloud.ParseCloudflare)
+	v6 := mustParseFile(t, "cloudflare-ips-v6.txt", cloud.ParseCloudflare)
+
+	got := cloud.Ranges(append(v4, v6...), nil)
+	want := rangesOf("103.21.244.0/22", "103.22.200.0/22", "173.245.48.0/20", "2400:cb00::/32", "2606:4700::/32")
+	if !slices.Equal(got, want) {
+		t.Errorf("Ranges, got %v, want %v", got, want)
+	}
+
+	for _, e := range v4 {
+		if e.Provider != cloud.Cloudflare || e.Service != "" || e.Region != "" {
+			t.Errorf("ParseCloudflare, got %+v", e)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		parse func(io.Reader) ([]cloud.Entry, error)
+		input string
+	}{
+		{"aws", cloud.ParseAWS, `{"prefixes":[{"ip_prefix":"3.5.140.0/33"}]}`},
+		{"aws", cloud.ParseAWS, `{"prefixes":`},
+		{"gcp", cloud.ParseGCP, `{"prefixes":[{"service":"Google Cloud"}]}`},
+		{"azure", cloud.ParseAzure, `{"values":[{"properties":{"addressPrefixes":["foo"]}}]}`},
+		{"cloudflare", cloud.ParseCloudflare, "173.245.48.0/20\nfoo\n"},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.parse(strings.NewReader(tt.input))
+		if err == nil {
+			t.Errorf("%s: parse(%q), expected error", tt.name, tt.input)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tt.name+":") {
+			t.Errorf("%s: parse(%q), got error %q, want prefix %q", tt.name, tt.input, err, tt.name+":")
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Command ipgrep prints the lines of text containing an IP address
+// in the given set of ranges, in the style of grepcidr.
+//
+// Usage:
+//
+//	ipgrep [-v] [-c] [-o] RANGES [file ...]
+//	ipgrep [-v] [-c] [-o] -f file [file ...]
+//
+// RANGES is a comma-separated list in the formats of iprange.FromString,
+// e.g. "10.0.0.0/8,192.168.1.5-192.168.1.9,2001:db8::/32".
+// With -f the ranges are read from file, see iprange.ReadList.
+// Without files, or for the file name "-", the lines are read from stdin.
+//
+// The exit status is 0 if a line is selected, 1 if no line is selected
+// and 2 if an error occurred.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/ipgrep"
+)
+
+const usage = `usage: ipgrep [-v] [-c] [-o] RANGES [file ...]
+       ipgrep [-v] [-c] [-o] -f file [file ...]
+`
+
+// exit codes, as grep
+const (
+	exitMatch   = 0
+	exitNoMatch = 1
+	exitError   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ipgrep", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	var opts ipgrep.Options
+	var patterns iprange.ListFlag
+	var usedF bool
+
+	fs.BoolVar(&opts.Invert, "v", false, "select lines without matching address")
+	fs.BoolVar(&opts.Count, "c", false, "print only the number of selected lines")
+	fs.BoolVar(&opts.OnlyMatching, "o", false, "print only the matching addresses")
+	fs.Func("f", "read the ranges from `file`, repeatable", func(name string) error {
+		usedF = true
+		return patterns.Set("@" + name)
+	})
+	noLabel := fs.Bool("h", false, "never prefix output lines with the file name")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitMatch
+		}
+		return exitError
+	}
+
+	files := fs.Args()
+	// with -f the ranges are given, even if empty
+	if !usedF {
+		if len(files) == 0 {
+			fs.Usage()
+			return exitError
+		}
+		if err := patterns.Set(files[0]); err != nil {
+			fmt.Fprintf(stderr, "ipgrep: %v\n", err)
+			return exitError
+		}
+		files = files[1:]
+	}
+
+	set := ipgrep.NewSet(patterns.Ranges)
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	withLabel := len(files) > 1 && !*noLabel
+
+	total := 0
+	for _, name := range files {
+		if withLabel {
+			opts.Label = name
+		}
+
+		n, err := grepFile(name, stdin, stdout, set, opts)
+		total += n
+		if err != nil {
+			fmt.Fprintf(stderr, "ipgrep: %v\n", err)
+			return exitError
+		}
+	}
+
+	if total == 0 {
+		return exitNoMatch
+	}
+	return exitMatch
+}
+
+// grepFile greps the named file, "-" is stdin.
+func grepFile(name string, stdin io.Reader, stdout io.Writer, set *ipgrep.Set, opts ipgrep.Options) (int, error) {
+	if name == "-" {
+		return ipgrep.Grep(stdout, stdin, set, opts)
+	}
+
+	fh, err := os.Open(name) //nolint:gosec // the file is named by the user on purpose
+	if err != nil {
+		return 0, err
+	}
+	defer fh.Close()
+
+	return ipgrep.Grep(stdout, fh, set, opts)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	ranges := filepath.Join(dir, "ranges.txt")
+	empty := filepath.Join(dir, "empty.txt")
+	logA := filepath.Join(dir, "a.log")
+	logB := filepath.Join(dir, "b.log")
+
+	for name, content := range map[string]string{
+		ranges: "# blocked\n10.0.0.0/24\n2001:db8::1-2001:db8::9\n",
+		logA:   "ok 192.168.1.1\nbad 10.0.0.7\n",
+		logB:   "bad 2001:db8::5\n",
+		empty:  "# nothing blocked\n",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdin := "a 10.0.0.1\nb 10.0.1.1\nc 2001:db8::2\n"
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+		code int
+	}{
+		{"pattern stdin", []string{"10.0.0.0/24"}, "a 10.0.0.1\n", exitMatch},
+		{"pattern list", []string{"10.0.1.0/24,2001:db8::/32"}, "b 10.0.1.1\nc 2001:db8::2\n", exitMatch},
+		{"invert", []string{"-v", "10.0.0.0/24"}, "b 10.0.1.1\nc 2001:db8::2\n", exitMatch},
+		{"count", []string{"-c", "10.0.0.0/16"}, "2\n", exitMatch},
+		{"only", []string{"-o", "10.0.0.0/16"}, "10.0.0.1\n10.0.1.1\n", exitMatch},
+		{"no match", []string{"172.16.0.0/12"}, "", exitNoMatch},
+		{"range file", []string{"-f", ranges, logA}, "bad 10.0.0.7\n", exitMatch},
+		{"labels", []string{"-f", ranges, logA, logB}, logA + ":bad 10.0.0.7\n" + logB + ":bad 2001:db8::5\n", exitMatch},
+		{"no labels", []string{"-h", "-f", ranges, logA, logB}, "bad 10.0.0.7\nbad 2001:db8::5\n", exitMatch},
+		{"no pattern", nil, "", exitError},
+		{"bad pattern", []string{"10.0.0.0/33"}, "", exitError},
+		{"empty range file", []string{"-f", empty, logA}, "", exitNoMatch},
+		{"missing range file", []string{"-f", ranges + ".missing"}, "", exitError},
+		{"missing file", []string{"10.0.0.0/8", logA + ".missing"}, "", exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(stdin), &stdout, &stderr)
+
+			if code != tt.code {
+				t.Fatalf("run(%q), exit code got %d, want %d, stderr: %s", tt.args, code, tt.code, stderr.String())
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run(%q)\ngot:\n%s\nwant:\n%s", tt.args, got, tt.want)
+			}
+		})
+	}
+}
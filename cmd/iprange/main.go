@@ -0,0 +1,639 @@
+// Command iprange is the command line front end to package iprange.
+//
+// Usage:
+//
+//	iprange <command> [flags] [file ...]
+//
+// The commands are:
+//
+//	merge      merge all input ranges into a sorted set
+//	subtract   remove the ranges of the following files from the ranges of the first file
+//	intersect  print the ranges contained in all input files
+//	cidr       merge and print the minimal set of CIDR prefixes
+//	count      merge and print the number of addresses
+//	contains   report for each query whether it is contained in the set of the input files
+//	split      merge and split into CIDR prefixes not larger than -len4 and -len6
+//	diff       print the ranges removed (-) and added (+) from the first to the second file
+//
+// Input files hold ranges in the formats of iprange.FromString, several
+// per line separated by commas or whitespace, '#' starts a comment.
+// Without files, or for the file name "-", the ranges are read from stdin.
+//
+// The output format is selected with -o: "range" (default), "cidr" or "json".
+// split refuses to split a single prefix into more than 2^20 prefixes.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"math/big"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gaissmai/iprange"
+)
+
+const usage = `usage: iprange <command> [flags] [file ...]
+
+commands:
+  merge      merge all input ranges into a sorted set
+  subtract   remove the ranges of the following files from the ranges of the first file
+  intersect  print the ranges contained in all input files
+  cidr       merge and print the minimal set of CIDR prefixes
+  count      merge and print the number of addresses
+  contains   iprange contains [-f file]... query...
+             report for each query whether it is contained in the set
+  split      merge and split into CIDR prefixes not larger than -len4 and -len6
+  diff       print the ranges removed (-) and added (+) from the first to the second file
+
+Run 'iprange <command> -h' for the flags of a command.
+`
+
+// exit codes
+const (
+	exitOK       = 0
+	exitFailure  = 1 // runtime error, or contains: not all queries contained
+	exitUsageErr = 2
+)
+
+// maxSplitBits limits split to 2^maxSplitBits prefixes per input prefix.
+const maxSplitBits = 20
+
+// errUsage signals a usage error, the message is already printed.
+var errUsage = errors.New("usage error")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsageErr
+	}
+
+	cmds := map[string]func(*command) error{
+		"merge":     cmdMerge,
+		"subtract":  cmdSubtract,
+		"intersect": cmdIntersect,
+		"cidr":      cmdMerge,
+		"count":     cmdCount,
+		"contains":  cmdContains,
+		"split":     cmdSplit,
+		"diff":      cmdDiff,
+	}
+
+	name := args[0]
+	fn, ok := cmds[name]
+	if !ok {
+		if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+			fmt.Fprint(stdout, usage)
+			return exitOK
+		}
+		fmt.Fprintf(stderr, "iprange: unknown command %q\n\n%s", name, usage)
+		return exitUsageErr
+	}
+
+	c := &command{
+		name:   name,
+		args:   args[1:],
+		stdin:  stdin,
+		stdout: stdout,
+		fs:     flag.NewFlagSet("iprange "+name, flag.ContinueOnError),
+
+		// cidr is merge, always splitting into prefixes
+		prefixes: name == "cidr",
+	}
+	c.fs.SetOutput(stderr)
+	c.fs.StringVar(&c.format, "o", "range", "output `format`: range, cidr or json")
+
+	err := fn(c)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsageErr
+	case errors.Is(err, errNotContained):
+		return exitFailure
+	default:
+		fmt.Fprintf(stderr, "iprange %s: %v\n", name, err)
+		return exitFailure
+	}
+}
+
+// command holds the state of a single subcommand invocation.
+type command struct {
+	name   string
+	args   []string
+	stdin  io.Reader
+	stdout io.Writer
+	fs     *flag.FlagSet
+	format string
+
+	// prefixes splits the output into CIDR prefixes,
+	// set for the cidr command and the cidr format.
+	prefixes bool
+}
+
+// parse parses the flags and validates the output format.
+func (c *command) parse() error {
+	if err := c.fs.Parse(c.args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+
+	switch c.format {
+	case "cidr":
+		c.prefixes = true
+		return nil
+	case "range", "json":
+		return nil
+	default:
+		fmt.Fprintf(c.fs.Output(), "invalid output format %q\n", c.format)
+		return errUsage
+	}
+}
+
+// read returns the ranges of the named file, "-" is stdin.
+func (c *command) read(name string) ([]iprange.IPRange, error) {
+	if name == "-" {
+		rs, err := iprange.ReadList(c.stdin)
+		if err != nil {
+			return nil, fmt.Errorf("stdin: %w", err)
+		}
+		return rs, nil
+	}
+
+	fh, err := os.Open(name) //nolint:gosec // the file is named by the user on purpose
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	rs, err := iprange.ReadList(fh)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return rs, nil
+}
+
+// readAll returns the ranges of all named files, stdin if names is empty.
+func (c *command) readAll(names []string) ([]iprange.IPRange, error) {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	var out []iprange.IPRange
+	for _, name := range names {
+		rs, err := c.read(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rs...)
+	}
+	return out, nil
+}
+
+// nArgs checks the minimal number of positional args.
+func (c *command) nArgs(n int, what string) error {
+	if c.fs.NArg() < n {
+		fmt.Fprintf(c.fs.Output(), "iprange %s: missing %s\n", c.name, what)
+		return errUsage
+	}
+	return nil
+}
+
+// strsOf returns the ranges as strings, split into prefixes if requested.
+func (c *command) strsOf(rs []iprange.IPRange) []string {
+	if !c.prefixes {
+		return strs(rs)
+	}
+
+	var out []string
+	for _, r := range rs {
+		for pfx := range r.Prefixes() {
+			out = append(out, pfx.String())
+		}
+	}
+	return out
+}
+
+func cmdMerge(c *command) error {
+	if err := c.parse(); err != nil {
+		return err
+	}
+
+	rs, err := c.readAll(c.fs.Args())
+	if err != nil {
+		return err
+	}
+
+	return c.writeStrs(c.strsOf(iprange.Merge(rs)))
+}
+
+func cmdSubtract(c *command) error {
+	if err := c.parse(); err != nil {
+		return err
+	}
+	if err := c.nArgs(1, "base file"); err != nil {
+		return err
+	}
+
+	base, err := c.read(c.fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// without exclusion files the exclusions are read from stdin
+	excl, err := c.readAll(c.fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+
+	return c.writeStrs(c.strsOf(subtract(iprange.Merge(base), iprange.Merge(excl))))
+}
+
+func cmdIntersect(c *command) error {
+	if err := c.parse(); err != nil {
+		return err
+	}
+	if err := c.nArgs(1, "input file"); err != nil {
+		return err
+	}
+
+	var out []iprange.IPRange
+	for i, name := range c.fs.Args() {
+		rs, err := c.read(name)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			out = iprange.Merge(rs)
+			continue
+		}
+		out = intersect(out, iprange.Merge(rs))
+	}
+
+	return c.writeStrs(c.strsOf(out))
+}
+
+func cmdCount(c *command) error {
+	if err := c.parse(); err != nil {
+		return err
+	}
+
+	rs, err := c.readAll(c.fs.Args())
+	if err != nil {
+		return err
+	}
+
+	sum := new(big.Int)
+	for _, r := range iprange.Merge(rs) {
+		sum.Add(sum, size(r))
+	}
+
+	if c.format == "json" {
+		return writeJSON(c.stdout, sum)
+	}
+
+	_, err = fmt.Fprintln(c.stdout, sum)
+	return err
+}
+
+// errNotContained is returned by contains if not all queries are contained.
+var errNotContained = errors.New("not contained")
+
+func cmdContains(c *command) error {
+	var files stringList
+	c.fs.Var(&files, "f", "read the set from `file`, repeatable, default stdin")
+
+	if err := c.parse(); err != nil {
+		return err
+	}
+	if err := c.nArgs(1, "query"); err != nil {
+		return err
+	}
+
+	rs, err := c.readAll(files)
+	if err != nil {
+		return err
+	}
+	set := iprange.Merge(rs)
+
+	type result struct {
+		Query     string `json:"query"`
+		Contained bool   `json:"contained"`
+	}
+
+	var results []result
+	allContained := true
+	for _, q := range c.fs.Args() {
+		r, err := iprange.FromString(q)
+		if err != nil {
+			return fmt.Errorf("invalid query %q: %w", q, err)
+		}
+
+		ok := contains(set, r)
+		allContained = allContained && ok
+		results = append(results, result{q, ok})
+	}
+
+	if c.format == "json" {
+		err = writeJSON(c.stdout, results)
+	} else {
+		for _, res := range results {
+			if _, err = fmt.Fprintf(c.stdout, "%s\t%t\n", res.Query, res.Contained); err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+	if !allContained {
+		return errNotContained
+	}
+	return nil
+}
+
+func cmdSplit(c *command) error {
+	len4 := c.fs.Int("len4", 24, "maximum IPv4 prefix `length`")
+	len6 := c.fs.Int("len6", 64, "maximum IPv6 prefix `length`")
+
+	if err := c.parse(); err != nil {
+		return err
+	}
+	if *len4 < 0 || *len4 > 32 || *len6 < 0 || *len6 > 128 {
+		fmt.Fprintf(c.fs.Output(), "iprange split: prefix length out of range\n")
+		return errUsage
+	}
+
+	rs, err := c.readAll(c.fs.Args())
+	if err != nil {
+		return err
+	}
+
+	rs = iprange.Merge(rs)
+	bitsOf := func(pfx netip.Prefix) int {
+		if pfx.Addr().Is4() {
+			return *len4
+		}
+		return *len6
+	}
+
+	// check before any output, a /32 split into /64 is 2^32 lines
+	for _, r := range rs {
+		for pfx := range r.Prefixes() {
+			if bitsOf(pfx)-pfx.Bits() > maxSplitBits {
+				return fmt.Errorf("%s splits into more than 2^%d prefixes", pfx, maxSplitBits)
+			}
+		}
+	}
+
+	// json needs the whole list, bounded by the check above
+	if c.format == "json" {
+		var out []string
+		for _, r := range rs {
+			for pfx := range r.Prefixes() {
+				for p := range split(pfx, bitsOf(pfx)) {
+					out = append(out, p.String())
+				}
+			}
+		}
+		return c.writeStrs(out)
+	}
+
+	for _, r := range rs {
+		for pfx := range r.Prefixes() {
+			for p := range split(pfx, bitsOf(pfx)) {
+				if _, err := fmt.Fprintln(c.stdout, p); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func cmdDiff(c *command) error {
+	if err := c.parse(); err != nil {
+		return err
+	}
+	if c.fs.NArg() != 2 {
+		fmt.Fprintf(c.fs.Output(), "iprange diff: need exactly two files\n")
+		return errUsage
+	}
+
+	old, err := c.read(c.fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	cur, err := c.read(c.fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	old, cur = iprange.Merge(old), iprange.Merge(cur)
+	removed := c.strsOf(subtract(old, cur))
+	added := c.strsOf(subtract(cur, old))
+
+	if c.format == "json" {
+		return writeJSON(c.stdout, struct {
+			Removed []string `json:"removed"`
+			Added   []string `json:"added"`
+		}{removed, added})
+	}
+
+	for _, s := range removed {
+		if _, err := fmt.Fprintln(c.stdout, "-"+s); err != nil {
+			return err
+		}
+	}
+	for _, s := range added {
+		if _, err := fmt.Fprintln(c.stdout, "+"+s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeStrs prints the strings line by line or as JSON array.
+func (c *command) writeStrs(ss []string) error {
+	if c.format == "json" {
+		if ss == nil {
+			ss = []string{}
+		}
+		return writeJSON(c.stdout, ss)
+	}
+
+	for _, s := range ss {
+		if _, err := fmt.Fprintln(c.stdout, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func strs(rs []iprange.IPRange) []string {
+	out := make([]string, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, r.String())
+	}
+	return out
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(s string) error { *l = append(*l, s); return nil }
+
+// subtract returns the merged set a without the merged set b.
+func subtract(a, b []iprange.IPRange) []iprange.IPRange {
+	var out []iprange.IPRange
+	j := 0
+	for _, r := range a {
+		first, last := r.Addrs()
+
+		// skip the ranges of b ending before r
+		for j < len(b) {
+			if _, bLast := b[j].Addrs(); !bLast.Less(first) {
+				break
+			}
+			j++
+		}
+
+		covered := false
+		for k := j; k < len(b); k++ {
+			bFirst, bLast := b[k].Addrs()
+			if last.Less(bFirst) {
+				break
+			}
+			if first.Less(bFirst) {
+				rest, _ := iprange.FromAddrs(first, bFirst.Prev())
+				out = append(out, rest)
+			}
+			if !bLast.Less(last) {
+				covered = true
+				break
+			}
+			first = bLast.Next()
+		}
+
+		if !covered {
+			rest, _ := iprange.FromAddrs(first, last)
+			out = append(out, rest)
+		}
+	}
+	return out
+}
+
+// intersect returns the intersection of the merged sets a and b.
+func intersect(a, b []iprange.IPRange) []iprange.IPRange {
+	var out []iprange.IPRange
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		aFirst, aLast := a[i].Addrs()
+		bFirst, bLast := b[j].Addrs()
+
+		lo := maxAddr(aFirst, bFirst)
+		hi := minAddr(aLast, bLast)
+		if r, err := iprange.FromAddrs(lo, hi); err == nil {
+			out = append(out, r)
+		}
+
+		if aLast.Less(bLast) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return out
+}
+
+// contains reports whether r is covered by the merged set.
+func contains(set []iprange.IPRange, r iprange.IPRange) bool {
+	first, last := r.Addrs()
+
+	// first range in set not ending before r
+	i := sort.Search(len(set), func(i int) bool {
+		_, l := set[i].Addrs()
+		return !l.Less(first)
+	})
+	if i == len(set) {
+		return false
+	}
+
+	f, l := set[i].Addrs()
+	return f.Compare(first) <= 0 && l.Compare(last) >= 0
+}
+
+// size returns the number of addresses in r.
+func size(r iprange.IPRange) *big.Int {
+	first, last := r.Addrs()
+	n := new(big.Int).SetBytes(last.AsSlice())
+	n.Sub(n, new(big.Int).SetBytes(first.AsSlice()))
+	return n.Add(n, big.NewInt(1))
+}
+
+// split returns the subprefixes of pfx with length bits, or pfx itself if not shorter.
+func split(pfx netip.Prefix, bits int) iter.Seq[netip.Prefix] {
+	return func(yield func(netip.Prefix) bool) {
+		if pfx.Bits() >= bits {
+			yield(pfx)
+			return
+		}
+
+		_, last := rangeOf(pfx).Addrs()
+		for addr := pfx.Addr(); ; {
+			p := netip.PrefixFrom(addr, bits)
+			if !yield(p) {
+				return
+			}
+
+			_, pLast := rangeOf(p).Addrs()
+			if pLast == last {
+				return
+			}
+			addr = pLast.Next()
+		}
+	}
+}
+
+// rangeOf returns the range of the valid prefix p.
+func rangeOf(p netip.Prefix) iprange.IPRange {
+	r, _ := iprange.FromPrefix(p)
+	return r
+}
+
+func maxAddr(a, b netip.Addr) netip.Addr {
+	if a.Less(b) {
+		return b
+	}
+	return a
+}
+
+func minAddr(a, b netip.Addr) netip.Addr {
+	if a.Less(b) {
+		return a
+	}
+	return b
+}
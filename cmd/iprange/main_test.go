@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+// writeFiles writes the contents into files in a temp dir and returns their names.
+func writeFiles(t *testing.T, contents ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+
+	var names []string
+	for i, content := range contents {
+		name := filepath.Join(dir, string(rune('a'+i))+".txt")
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	files := writeFiles(t,
+		"10.0.0.0/24\n10.0.1.0-10.0.1.9 # comment\n2001:db8::/32\n",
+		"10.0.0.128/25, 2001:db8:1::/48\n",
+		"10.0.0.0/25\n10.0.1.0/24\n",
+	)
+	a, b, c := files[0], files[1], files[2]
+
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  string
+		code  int
+	}{
+		{
+			name:  "merge stdin",
+			args:  []string{"merge"},
+			stdin: "10.0.0.2\n10.0.0.1\n10.0.0.3,::1\n",
+			want:  "10.0.0.1-10.0.0.3\n::1/128\n",
+		},
+		{
+			name: "merge files",
+			args: []string{"merge", a, c},
+			want: "10.0.0.0/23\n2001:db8::/32\n",
+		},
+		{
+			name: "merge cidr output",
+			args: []string{"merge", "-o", "cidr", a},
+			want: "10.0.0.0/24\n10.0.1.0/29\n10.0.1.8/31\n2001:db8::/32\n",
+		},
+		{
+			name: "merge json output",
+			args: []string{"merge", "-o", "json", c},
+			want: "[\n  \"10.0.0.0/25\",\n  \"10.0.1.0/24\"\n]\n",
+		},
+		{
+			name:  "cidr",
+			args:  []string{"cidr"},
+			stdin: "10.0.0.1-10.0.0.3",
+			want:  "10.0.0.1/32\n10.0.0.2/31\n",
+		},
+		{
+			name:  "cidr json",
+			args:  []string{"cidr", "-o", "json"},
+			stdin: "10.0.0.1-10.0.0.3",
+			want:  "[\n  \"10.0.0.1/32\",\n  \"10.0.0.2/31\"\n]\n",
+		},
+		{
+			name: "subtract",
+			args: []string{"subtract", a, b},
+			want: "10.0.0.0/25\n10.0.1.0-10.0.1.9\n2001:db8::/48\n2001:db8:2::-2001:db8:ffff:ffff:ffff:ffff:ffff:ffff\n",
+		},
+		{
+			name:  "subtract stdin",
+			args:  []string{"subtract", c},
+			stdin: "10.0.1.0/25",
+			want:  "10.0.0.0/25\n10.0.1.128/25\n",
+		},
+		{
+			name: "intersect",
+			args: []string{"intersect", a, b},
+			want: "10.0.0.128/25\n2001:db8:1::/48\n",
+		},
+		{
+			name: "intersect three",
+			args: []string{"intersect", a, c, b},
+			want: "",
+		},
+		{
+			name:  "count",
+			args:  []string{"count"},
+			stdin: "10.0.0.0/24 10.0.0.128/25 ::/126",
+			want:  "260\n",
+		},
+		{
+			name:  "count v6",
+			args:  []string{"count"},
+			stdin: "::/0",
+			want:  "340282366920938463463374607431768211456\n",
+		},
+		{
+			name: "contains",
+			args: []string{"contains", "-f", a, "-f", b, "10.0.1.5", "10.0.0.250-10.0.1.9"},
+			want: "10.0.1.5\ttrue\n10.0.0.250-10.0.1.9\ttrue\n",
+		},
+		{
+			name: "contains not",
+			args: []string{"contains", "-f", a, "10.0.1.5", "10.0.1.10"},
+			want: "10.0.1.5\ttrue\n10.0.1.10\tfalse\n",
+			code: exitFailure,
+		},
+		{
+			name:  "split",
+			args:  []string{"split", "-len4", "26", "-len6", "34"},
+			stdin: "10.0.0.0/25 10.0.0.128-10.0.0.130 2001:db8::/32",
+			want:  "10.0.0.0/26\n10.0.0.64/26\n10.0.0.128/31\n10.0.0.130/32\n2001:db8::/34\n2001:db8:4000::/34\n2001:db8:8000::/34\n2001:db8:c000::/34\n",
+		},
+		{
+			name:  "split default len6",
+			args:  []string{"split"},
+			stdin: "2001:db8::/62",
+			want:  "2001:db8::/64\n2001:db8:0:1::/64\n2001:db8:0:2::/64\n2001:db8:0:3::/64\n",
+		},
+		{
+			name:  "split json",
+			args:  []string{"split", "-len4", "25", "-o", "json"},
+			stdin: "10.0.0.0/24",
+			want:  "[\n  \"10.0.0.0/25\",\n  \"10.0.0.128/25\"\n]\n",
+		},
+		{
+			name: "diff",
+			args: []string{"diff", a, c},
+			want: "-10.0.0.128/25\n-2001:db8::/32\n+10.0.1.10-10.0.1.255\n",
+		},
+		{
+			name: "diff json",
+			args: []string{"diff", "-o", "json", c, c},
+			want: "{\n  \"removed\": [],\n  \"added\": []\n}\n",
+		},
+		{name: "no command", args: nil, code: exitUsageErr},
+		{name: "unknown command", args: []string{"frobnicate"}, code: exitUsageErr},
+		{name: "unknown flag", args: []string{"merge", "-x"}, code: exitUsageErr},
+		{name: "unknown format", args: []string{"merge", "-o", "xml"}, code: exitUsageErr},
+		{name: "diff one file", args: []string{"diff", a}, code: exitUsageErr},
+		{name: "subtract no file", args: []string{"subtract"}, code: exitUsageErr},
+		{name: "contains no query", args: []string{"contains"}, code: exitUsageErr},
+		{name: "split bad len", args: []string{"split", "-len4", "33"}, code: exitUsageErr},
+		{name: "split too many", args: []string{"split", "-len4", "9"}, stdin: "10.0.0.0/8 2001:db8::/32", code: exitFailure},
+		{name: "missing file", args: []string{"merge", a + ".missing"}, code: exitFailure},
+		{name: "invalid input", args: []string{"merge"}, stdin: "10.0.0.1\n10.0.0.", code: exitFailure},
+		{name: "invalid query", args: []string{"contains", "-f", a, "10.0.0."}, code: exitFailure},
+		{name: "help", args: []string{"help"}, want: usage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			if code != tt.code {
+				t.Fatalf("run(%q), exit code got %d, want %d, stderr: %s", tt.args, code, tt.code, stderr.String())
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run(%q)\ngot:\n%s\nwant:\n%s", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b string
+	}{
+		{"10.0.0.0/24", ""},
+		{"", "10.0.0.0/24"},
+		{"10.0.0.0/24", "10.0.0.0/24"},
+		{"10.0.0.0/24", "10.0.0.5,10.0.0.7-10.0.0.9,10.0.0.255"},
+		{"10.0.0.0/24,10.0.2.0/24", "10.0.0.128-10.0.2.127"},
+		{"10.0.0.0/24,10.0.1.0/25", "9.0.0.0/8,10.0.1.0/26,11.0.0.0/8"},
+		{"255.255.255.0/24,ffff::/16", "255.255.255.255,ffff:ffff::/32"},
+		{"0.0.0.0/0,::/0", "10.0.0.0/8,2001:db8::/32"},
+	}
+
+	parse := func(s string) []iprange.IPRange {
+		var rs []iprange.IPRange
+		if s != "" {
+			for _, f := range strings.Split(s, ",") {
+				r, err := iprange.FromString(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rs = append(rs, r)
+			}
+		}
+		return iprange.Merge(rs)
+	}
+
+	for _, tt := range tests {
+		a, b := parse(tt.a), parse(tt.b)
+
+		var want []iprange.IPRange
+		for _, r := range a {
+			want = append(want, r.Remove(b)...)
+		}
+
+		if got := subtract(a, b); !slices.Equal(got, want) {
+			t.Errorf("subtract(%s, %s), got %v, want %v", tt.a, tt.b, got, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package iprange_test
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"net/netip"
 
@@ -237,3 +238,50 @@ func mustParse(s string) iprange.IPRange {
 	}
 	return r
 }
+
+func ExampleJSONObject() {
+	type Config struct {
+		Allowed iprange.JSONObject `json:"allowed"`
+	}
+
+	c := Config{Allowed: iprange.JSONObject{mustParse("10.0.0.3-10.0.0.9")}}
+	data, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(data))
+
+	// peers sending the plain string form are understood as well
+	var c2 Config
+	if err := json.Unmarshal([]byte(`{"allowed":"10.0.0.3-10.0.0.9"}`), &c2); err != nil {
+		panic(err)
+	}
+	fmt.Println(c2.Allowed)
+
+	// Output:
+	// {"allowed":{"start":"10.0.0.3","end":"10.0.0.9","cidrs":["10.0.0.3/32","10.0.0.4/30","10.0.0.8/31"]}}
+	// 10.0.0.3-10.0.0.9
+}
+
+func ExampleListFlag() {
+	fs := flag.NewFlagSet("example", flag.ContinueOnError)
+
+	var allow iprange.ListFlag
+	fs.Var(&allow, "allow", "allowed `ranges`, repeatable")
+
+	err := fs.Parse([]string{
+		"--allow", "10.0.0.0/8",
+		"--allow", "192.168.1.5-192.168.1.9,192.168.1.10",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, r := range allow.Ranges {
+		fmt.Println(r)
+	}
+
+	// Output:
+	// 10.0.0.0/8
+	// 192.168.1.5-192.168.1.10
+}
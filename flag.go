@@ -0,0 +1,138 @@
+package iprange
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Flag implements flag.Value for a single IPRange, parsed by UnmarshalText.
+// Unlike UnmarshalText, Set replaces a non-zero value, e.g. a default.
+//
+//	var r iprange.Flag
+//	flag.Var(&r, "net", "`range` to scan")
+type Flag struct {
+	Range IPRange
+}
+
+// String implements flag.Value.
+func (f *Flag) String() string {
+	if f == nil || !f.Range.IsValid() {
+		return ""
+	}
+	return f.Range.String()
+}
+
+// Set implements flag.Value.
+func (f *Flag) Set(s string) error {
+	var r IPRange
+	if err := r.UnmarshalText([]byte(s)); err != nil {
+		return fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	if !r.IsValid() {
+		return errors.New("empty range")
+	}
+
+	f.Range = r
+	return nil
+}
+
+// Get implements flag.Getter, it returns the IPRange.
+func (f *Flag) Get() any {
+	return f.Range
+}
+
+// ListFlag implements flag.Value for a set of IPRanges.
+// The flag may be repeated and each value may hold a comma-separated list of ranges.
+// A value "@file" reads the ranges from file with the rules of ReadList.
+// The collected ranges are merged into a sorted set, see Merge.
+//
+//	var allow iprange.ListFlag
+//	flag.Var(&allow, "allow", "allowed `ranges`, repeatable")
+//
+//	// --allow 10.0.0.0/8 --allow 192.168.1.5-192.168.1.9,192.168.1.10 --allow @allow.txt
+type ListFlag struct {
+	Ranges []IPRange
+}
+
+// String implements flag.Value, it returns the comma-separated set.
+func (f *ListFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	ss := make([]string, 0, len(f.Ranges))
+	for _, r := range f.Ranges {
+		ss = append(ss, r.String())
+	}
+	return strings.Join(ss, ",")
+}
+
+// Set implements flag.Value, it adds the ranges of s to the set.
+func (f *ListFlag) Set(s string) error {
+	var rs []IPRange
+
+	if name, ok := strings.CutPrefix(s, "@"); ok {
+		fh, err := os.Open(name) //nolint:gosec // the file is named by the user on purpose
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+
+		if rs, err = ReadList(fh); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	} else {
+		for tok := range strings.SplitSeq(s, ",") {
+			r, err := FromString(strings.TrimSpace(tok))
+			if err != nil {
+				return fmt.Errorf("invalid range %q: %w", tok, err)
+			}
+			rs = append(rs, r)
+		}
+	}
+
+	f.Ranges = Merge(append(f.Ranges, rs...))
+	return nil
+}
+
+// Get implements flag.Getter, it returns the merged []IPRange.
+func (f *ListFlag) Get() any {
+	return f.Ranges
+}
+
+// ReadList reads ranges in the formats of FromString from rd,
+// and returns them in input order, they are not merged.
+//
+// The input may hold several ranges per line, separated by commas or whitespace.
+// Empty lines and comments, starting with '#' up to the end of the line, are ignored.
+// Errors report the line number of the invalid range.
+func ReadList(rd io.Reader) ([]IPRange, error) {
+	var out []IPRange
+
+	scanner := bufio.NewScanner(rd)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		fields := strings.FieldsFunc(line, func(c rune) bool {
+			return c == ',' || c == ' ' || c == '\t' || c == '\r'
+		})
+
+		for _, tok := range fields {
+			r, err := FromString(tok)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid range %q: %w", lineNo, tok, err)
+			}
+			out = append(out, r)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
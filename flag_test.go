@@ -0,0 +1,132 @@
+package iprange_test
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestFlag(t *testing.T) {
+	t.Parallel()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	r := iprange.Flag{Range: mustFromString("10.0.0.0/8")} // default
+	fs.Var(&r, "net", "range")
+
+	if err := fs.Parse([]string{"-net", "192.168.1.5-192.168.1.9"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := mustFromString("192.168.1.5-192.168.1.9")
+	if r.Range != want {
+		t.Errorf("Flag, got %v, want %v", r.Range, want)
+	}
+	if got := fs.Lookup("net").Value.(flag.Getter).Get(); got != want {
+		t.Errorf("Flag.Get, got %v, want %v", got, want)
+	}
+
+	for _, arg := range []string{"", "10.0.0.1-", "fe80::1%eth0"} {
+		if err := r.Set(arg); err == nil {
+			t.Errorf("Flag.Set(%q), expected error, got nil", arg)
+		}
+	}
+	if r.Range != want {
+		t.Errorf("Flag changed on error, got %v, want %v", r.Range, want)
+	}
+
+	var zero *iprange.Flag
+	if s := zero.String(); s != "" {
+		t.Errorf("nil Flag.String, got %q, want empty string", s)
+	}
+}
+
+func TestListFlag(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "allow.txt")
+
+	content := `# office networks
+192.168.1.10
+
+2001:db8::/32, 2001:db9::/32  # comment
+`
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var allow iprange.ListFlag
+	fs.Var(&allow, "allow", "ranges")
+
+	args := []string{
+		"-allow", "10.0.0.0/8",
+		"-allow", "192.168.1.5-192.168.1.9, 10.1.0.0/16",
+		"-allow", "@" + file,
+	}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []iprange.IPRange{
+		mustFromString("10.0.0.0/8"),
+		mustFromString("192.168.1.5-192.168.1.10"),
+		mustFromString("2001:db8::/31"),
+	}
+	if !slices.Equal(allow.Ranges, want) {
+		t.Errorf("ListFlag, got %v, want %v", allow.Ranges, want)
+	}
+
+	wantString := "10.0.0.0/8,192.168.1.5-192.168.1.10,2001:db8::/31"
+	if got := allow.String(); got != wantString {
+		t.Errorf("ListFlag.String, got %q, want %q", got, wantString)
+	}
+
+	for _, arg := range []string{"", "10.0.0.0/8,", "10.0.0.9-10.0.0.1", "@" + filepath.Join(dir, "missing.txt")} {
+		if err := allow.Set(arg); err == nil {
+			t.Errorf("ListFlag.Set(%q), expected error, got nil", arg)
+		}
+	}
+	if !slices.Equal(allow.Ranges, want) {
+		t.Errorf("ListFlag changed on error, got %v, want %v", allow.Ranges, want)
+	}
+
+	var zero *iprange.ListFlag
+	if s := zero.String(); s != "" {
+		t.Errorf("nil ListFlag.String, got %q, want empty string", s)
+	}
+}
+
+func TestReadList(t *testing.T) {
+	t.Parallel()
+	input := "10.0.0.1 10.0.0.0/8\t::1\r\n\n  # only a comment\n1.2.3.4-1.2.3.5,5.6.7.8 # trailing\n"
+
+	got, err := iprange.ReadList(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []iprange.IPRange{
+		mustFromString("10.0.0.1"),
+		mustFromString("10.0.0.0/8"),
+		mustFromString("::1"),
+		mustFromString("1.2.3.4-1.2.3.5"),
+		mustFromString("5.6.7.8"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ReadList, got %v, want %v", got, want)
+	}
+
+	_, err = iprange.ReadList(strings.NewReader("10.0.0.1\n\n10.0.0.256\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("ReadList, expected error with line number, got %v", err)
+	}
+}
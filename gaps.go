@@ -0,0 +1,90 @@
+package iprange
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// GapStats are the address counts of Gaps.
+type GapStats struct {
+	Total *big.Int // addresses within the container
+	Used  *big.Int // used addresses within the container, overlaps counted once
+	Free  *big.Int // free addresses, Total - Used
+	Gaps  int      // number of free ranges
+}
+
+// Gaps returns the free ranges within the container, the container without
+// the used ranges, as with Remove, and the address counts.
+// Used ranges partially or entirely outside the container are clipped.
+func Gaps(within IPRange, used []IPRange) ([]IPRange, GapStats) {
+	free := within.Remove(used)
+
+	stats := GapStats{
+		Total: new(big.Int),
+		Used:  new(big.Int),
+		Free:  new(big.Int),
+		Gaps:  len(free),
+	}
+	if within == zeroValue {
+		return free, stats
+	}
+
+	stats.Total = within.size()
+	for _, r := range free {
+		stats.Free.Add(stats.Free, r.size())
+	}
+	stats.Used.Sub(stats.Total, stats.Free)
+
+	return free, stats
+}
+
+// LargestFree returns the largest free aligned prefix within the container,
+// the lowest on ties. It returns false if there is no free address.
+func LargestFree(within IPRange, used []IPRange) (netip.Prefix, bool) {
+	free, _ := Gaps(within, used)
+
+	var best netip.Prefix
+	for _, r := range free {
+		for p := range r.Prefixes() {
+			if !best.IsValid() || p.Bits() < best.Bits() {
+				best = p
+			}
+		}
+	}
+	return best, best.IsValid()
+}
+
+// FreeHistogram returns the number of free aligned blocks by prefix length,
+// counting the minimal prefixes of the free ranges within the container,
+// see Prefixes.
+func FreeHistogram(within IPRange, used []IPRange) map[int]int {
+	free, _ := Gaps(within, used)
+
+	hist := make(map[int]int)
+	for _, r := range free {
+		for p := range r.Prefixes() {
+			hist[p.Bits()]++
+		}
+	}
+	return hist
+}
+
+// CanFit returns the free aligned prefix of length bits with the lowest
+// address within the container. It returns false if no such prefix is free,
+// or if bits is out of range for the address family of the container.
+func CanFit(within IPRange, used []IPRange, bits int) (netip.Prefix, bool) {
+	if !within.IsValid() || bits < 0 || bits > within.first.BitLen() {
+		return netip.Prefix{}, false
+	}
+
+	free, _ := Gaps(within, used)
+
+	for _, r := range free {
+		for p := range r.Prefixes() {
+			if p.Bits() <= bits {
+				return netip.PrefixFrom(p.Addr(), bits), true
+			}
+		}
+	}
+	return netip.Prefix{}, false
+}
@@ -0,0 +1,131 @@
+package iprange_test
+
+import (
+	"maps"
+	"math/big"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestGaps(t *testing.T) {
+	t.Parallel()
+	within := mustFromString("10.0.0.0/22")
+	used := []iprange.IPRange{
+		mustFromString("10.0.0.0/24"),
+		mustFromString("10.0.0.128/25"), // overlapping, counted once
+		mustFromString("10.0.2.0-10.0.2.9"),
+		mustFromString("10.0.3.255-10.0.4.255"), // clipped
+	}
+
+	gaps, stats := iprange.Gaps(within, used)
+
+	want := []iprange.IPRange{
+		mustFromString("10.0.1.0/24"),
+		mustFromString("10.0.2.10-10.0.3.254"),
+	}
+	if !slices.Equal(gaps, want) {
+		t.Errorf("Gaps, got %v, want %v", gaps, want)
+	}
+
+	if stats.Total.Cmp(big.NewInt(1024)) != 0 || stats.Used.Cmp(big.NewInt(267)) != 0 ||
+		stats.Free.Cmp(big.NewInt(757)) != 0 || stats.Gaps != 2 {
+		t.Errorf("Gaps, got stats %v %v %v %d, want 1024 267 757 2", stats.Total, stats.Used, stats.Free, stats.Gaps)
+	}
+
+	// IPv6 sizes beyond 64 bits
+	_, stats = iprange.Gaps(mustFromString("::/0"), []iprange.IPRange{mustFromString("::/1")})
+	if want := new(big.Int).Lsh(big.NewInt(1), 127); stats.Free.Cmp(want) != 0 || stats.Used.Cmp(want) != 0 {
+		t.Errorf("Gaps(::/0), got free %v used %v, want %v", stats.Free, stats.Used, want)
+	}
+
+	gaps, stats = iprange.Gaps(iprange.IPRange{}, used)
+	if gaps != nil || stats.Total.Sign() != 0 || stats.Gaps != 0 {
+		t.Errorf("Gaps(zero value), got %v %+v", gaps, stats)
+	}
+}
+
+func TestLargestFree(t *testing.T) {
+	t.Parallel()
+	within := mustFromString("10.0.0.0/16")
+	used := []iprange.IPRange{
+		mustFromString("10.0.0.0/17"),
+		mustFromString("10.0.192.0/24"),
+	}
+
+	got, ok := iprange.LargestFree(within, used)
+	if want := netip.MustParsePrefix("10.0.128.0/18"); !ok || got != want {
+		t.Errorf("LargestFree, got %v %v, want %v", got, ok, want)
+	}
+
+	if got, ok := iprange.LargestFree(within, []iprange.IPRange{within}); ok {
+		t.Errorf("LargestFree of full container, got %v, expected not ok", got)
+	}
+}
+
+func TestFreeHistogram(t *testing.T) {
+	t.Parallel()
+	within := mustFromString("10.0.0.0/24")
+	used := []iprange.IPRange{mustFromString("10.0.0.0/26"), mustFromString("10.0.0.200")}
+
+	// free 10.0.0.64-10.0.0.199: /26 /26 /29
+	// and 10.0.0.201-10.0.0.255: /32 /31 /30 /28 /27
+	want := map[int]int{26: 2, 27: 1, 28: 1, 29: 1, 30: 1, 31: 1, 32: 1}
+	got := iprange.FreeHistogram(within, used)
+	if !maps.Equal(got, want) {
+		t.Errorf("FreeHistogram, got %v, want %v", got, want)
+	}
+}
+
+func TestCanFit(t *testing.T) {
+	t.Parallel()
+	within := mustFromString("10.0.0.0/22")
+	used := []iprange.IPRange{mustFromString("10.0.0.0/24"), mustFromString("10.0.2.0/23")}
+
+	tests := []struct {
+		bits int
+		want string
+		ok   bool
+	}{
+		{24, "10.0.1.0/24", true},
+		{26, "10.0.1.0/26", true},
+		{32, "10.0.1.0/32", true},
+		{23, "", false},
+		{8, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := iprange.CanFit(within, used, tt.bits)
+		if ok != tt.ok {
+			t.Errorf("CanFit(/%d), got %v %v, want ok %v", tt.bits, got, ok, tt.ok)
+			continue
+		}
+		if ok && got != netip.MustParsePrefix(tt.want) {
+			t.Errorf("CanFit(/%d), got %v, want %v", tt.bits, got, tt.want)
+		}
+	}
+
+	// bits out of range for the address family
+	for _, tt := range []struct {
+		within string
+		bits   int
+	}{
+		{"10.0.0.0/24", 33},
+		{"10.0.0.0/24", -1},
+		{"2001:db8::/64", 129},
+		{"2001:db8::/64", 200},
+		{"2001:db8::/64", -8},
+	} {
+		if got, ok := iprange.CanFit(mustFromString(tt.within), nil, tt.bits); ok {
+			t.Errorf("CanFit(%s, nil, %d), got %v, want not ok", tt.within, tt.bits, got)
+		}
+	}
+	if got, ok := iprange.CanFit(iprange.IPRange{}, nil, 0); ok {
+		t.Errorf("CanFit(invalid, nil, 0), got %v, want not ok", got)
+	}
+	if got, ok := iprange.CanFit(mustFromString("2001:db8::/64"), nil, 128); !ok || got != netip.MustParsePrefix("2001:db8::/128") {
+		t.Errorf("CanFit(2001:db8::/64, nil, 128), got %v %v, want 2001:db8::/128", got, ok)
+	}
+}
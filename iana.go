@@ -0,0 +1,234 @@
+package iprange
+
+import (
+	"net/netip"
+	"slices"
+	"sync"
+)
+
+// SpecialPurpose is an entry of the IANA IPv4 or IPv6 Special-Purpose
+// Address Registry, see RFC 6890 and the registries at
+// https://www.iana.org/assignments/iana-ipv4-special-registry and
+// https://www.iana.org/assignments/iana-ipv6-special-registry.
+//
+// Entries marked as deprecated in the registries are not included.
+// A value of N/A in the registry is reported as false, except for the
+// GloballyReachable flag of 6to4 and Teredo, where it depends on the
+// embedded IPv4 address and is reported as true.
+type SpecialPurpose struct {
+	Range IPRange
+	Name  string
+	RFC   string
+
+	Source             bool
+	Destination        bool
+	Forwardable        bool
+	GloballyReachable  bool
+	ReservedByProtocol bool
+}
+
+// Classification is the result of Classify.
+type Classification struct {
+	// Entries are the special-purpose entries overlapping the address or range,
+	// less specific entries first.
+	Entries []SpecialPurpose
+
+	// Forwardable and GloballyReachable are true if they hold for all
+	// addresses, ReservedByProtocol is true if it holds for any address.
+	// The most specific entry decides for an address, addresses not
+	// covered by any entry are forwardable and globally reachable.
+	Forwardable        bool
+	GloballyReachable  bool
+	ReservedByProtocol bool
+}
+
+// specialPurposeTable is the compiled-in registry data, sorted by range.
+//
+// Flags: source, destination, forwardable, globally reachable, reserved-by-protocol.
+var specialPurposeTable = []struct {
+	prefix string
+	name   string
+	rfc    string
+	flags  [5]bool
+}{
+	// IPv4
+	{"0.0.0.0/8", "This network", "RFC 791", [5]bool{true, false, false, false, true}},
+	{"0.0.0.0/32", "This host on this network", "RFC 1122", [5]bool{true, false, false, false, true}},
+	{"10.0.0.0/8", "Private-Use", "RFC 1918", [5]bool{true, true, true, false, false}},
+	{"100.64.0.0/10", "Shared Address Space", "RFC 6598", [5]bool{true, true, true, false, false}},
+	{"127.0.0.0/8", "Loopback", "RFC 1122", [5]bool{false, false, false, false, true}},
+	{"169.254.0.0/16", "Link Local", "RFC 3927", [5]bool{true, true, false, false, true}},
+	{"172.16.0.0/12", "Private-Use", "RFC 1918", [5]bool{true, true, true, false, false}},
+	{"192.0.0.0/24", "IETF Protocol Assignments", "RFC 6890", [5]bool{false, false, false, false, false}},
+	{"192.0.0.0/29", "IPv4 Service Continuity Prefix", "RFC 7335", [5]bool{true, true, true, false, false}},
+	{"192.0.0.8/32", "IPv4 dummy address", "RFC 7600", [5]bool{true, false, false, false, false}},
+	{"192.0.0.9/32", "Port Control Protocol Anycast", "RFC 7723", [5]bool{true, true, true, true, false}},
+	{"192.0.0.10/32", "Traversal Using Relays around NAT Anycast", "RFC 8155", [5]bool{true, true, true, true, false}},
+	{"192.0.0.170/32", "NAT64/DNS64 Discovery", "RFC 8880", [5]bool{false, false, false, false, true}},
+	{"192.0.0.171/32", "NAT64/DNS64 Discovery", "RFC 8880", [5]bool{false, false, false, false, true}},
+	{"192.0.2.0/24", "Documentation (TEST-NET-1)", "RFC 5737", [5]bool{false, false, false, false, false}},
+	{"192.31.196.0/24", "AS112-v4", "RFC 7535", [5]bool{true, true, true, true, false}},
+	{"192.52.193.0/24", "AMT", "RFC 7450", [5]bool{true, true, true, true, false}},
+	{"192.168.0.0/16", "Private-Use", "RFC 1918", [5]bool{true, true, true, false, false}},
+	{"192.175.48.0/24", "Direct Delegation AS112 Service", "RFC 7534", [5]bool{true, true, true, true, false}},
+	{"198.18.0.0/15", "Benchmarking", "RFC 2544", [5]bool{true, true, true, false, false}},
+	{"198.51.100.0/24", "Documentation (TEST-NET-2)", "RFC 5737", [5]bool{false, false, false, false, false}},
+	{"203.0.113.0/24", "Documentation (TEST-NET-3)", "RFC 5737", [5]bool{false, false, false, false, false}},
+	{"240.0.0.0/4", "Reserved", "RFC 1112", [5]bool{false, false, false, false, true}},
+	{"255.255.255.255/32", "Limited Broadcast", "RFC 919", [5]bool{false, true, false, false, true}},
+
+	// IPv6
+	{"::/128", "Unspecified Address", "RFC 4291", [5]bool{true, false, false, false, true}},
+	{"::1/128", "Loopback Address", "RFC 4291", [5]bool{false, false, false, false, true}},
+	{"::ffff:0:0/96", "IPv4-mapped Address", "RFC 4291", [5]bool{false, false, false, false, true}},
+	{"64:ff9b::/96", "IPv4-IPv6 Translat.", "RFC 6052", [5]bool{true, true, true, true, false}},
+	{"64:ff9b:1::/48", "IPv4-IPv6 Translat.", "RFC 8215", [5]bool{true, true, true, false, false}},
+	{"100::/64", "Discard-Only Address Block", "RFC 6666", [5]bool{true, true, true, false, false}},
+	{"2001::/23", "IETF Protocol Assignments", "RFC 2928", [5]bool{false, false, false, false, false}},
+	{"2001::/32", "TEREDO", "RFC 4380", [5]bool{true, true, true, true, false}},
+	{"2001:1::1/128", "Port Control Protocol Anycast", "RFC 7723", [5]bool{true, true, true, true, false}},
+	{"2001:1::2/128", "Traversal Using Relays around NAT Anycast", "RFC 8155", [5]bool{true, true, true, true, false}},
+	{"2001:1::3/128", "DNS-SD Service Registration Protocol Anycast", "RFC 9665", [5]bool{true, true, true, true, false}},
+	{"2001:2::/48", "Benchmarking", "RFC 5180", [5]bool{true, true, true, false, false}},
+	{"2001:3::/32", "AMT", "RFC 7450", [5]bool{true, true, true, true, false}},
+	{"2001:4:112::/48", "AS112-v6", "RFC 7535", [5]bool{true, true, true, true, false}},
+	{"2001:20::/28", "ORCHIDv2", "RFC 7343", [5]bool{true, true, true, true, false}},
+	{"2001:30::/28", "Drone Remote ID Protocol Entity Tags (DETs) Prefix", "RFC 9374", [5]bool{true, true, true, true, false}},
+	{"2001:db8::/32", "Documentation", "RFC 3849", [5]bool{false, false, false, false, false}},
+	{"2002::/16", "6to4", "RFC 3056", [5]bool{true, true, true, true, false}},
+	{"2620:4f:8000::/48", "Direct Delegation AS112 Service", "RFC 7534", [5]bool{true, true, true, true, false}},
+	{"3fff::/20", "Documentation", "RFC 9637", [5]bool{false, false, false, false, false}},
+	{"5f00::/16", "Segment Routing (SRv6) SIDs", "RFC 9602", [5]bool{true, true, true, false, false}},
+	{"fc00::/7", "Unique-Local", "RFC 4193", [5]bool{true, true, true, false, false}},
+	{"fe80::/10", "Link-Local Unicast", "RFC 4291", [5]bool{true, true, false, false, true}},
+}
+
+// multicast is not special-purpose, but never a unicast source or destination.
+var multicast = []string{"224.0.0.0/4", "ff00::/8"}
+
+// globalUnicast is the address space to allocate unicast addresses from.
+var globalUnicast = []string{"0.0.0.0/0", "2000::/3"}
+
+var specialPurpose = sync.OnceValue(func() []SpecialPurpose {
+	out := make([]SpecialPurpose, 0, len(specialPurposeTable))
+	for _, e := range specialPurposeTable {
+		out = append(out, SpecialPurpose{
+			Range:              mustPrefix(e.prefix),
+			Name:               e.name,
+			RFC:                e.rfc,
+			Source:             e.flags[0],
+			Destination:        e.flags[1],
+			Forwardable:        e.flags[2],
+			GloballyReachable:  e.flags[3],
+			ReservedByProtocol: e.flags[4],
+		})
+	}
+	return out
+})
+
+var bogons = sync.OnceValue(func() []IPRange {
+	var rs []IPRange
+	for _, e := range specialPurpose() {
+		if !e.GloballyReachable {
+			rs = append(rs, effective(e, e.Range)...)
+		}
+	}
+	for _, s := range multicast {
+		rs = append(rs, mustPrefix(s))
+	}
+	return Merge(rs)
+})
+
+// SpecialPurposeRegistry returns the entries of the IPv4 and IPv6
+// Special-Purpose Address Registries, sorted by range.
+func SpecialPurposeRegistry() []SpecialPurpose {
+	return slices.Clone(specialPurpose())
+}
+
+// Classify returns the special-purpose entries for an address or a range
+// and the combined flags.
+// An invalid address or range returns the zero Classification.
+func Classify[T netip.Addr | IPRange](x T) Classification {
+	var r IPRange
+	switch v := any(x).(type) {
+	case netip.Addr:
+		r, _ = FromAddrs(v, v)
+	case IPRange:
+		r = v
+	}
+	if !r.IsValid() {
+		return Classification{}
+	}
+
+	c := Classification{
+		Forwardable:       true,
+		GloballyReachable: true,
+	}
+
+	for _, e := range specialPurpose() {
+		if e.Range.isDisjunct(r) {
+			continue
+		}
+		c.Entries = append(c.Entries, e)
+
+		// only the part not overridden by more specific entries counts
+		if len(effective(e, r)) == 0 {
+			continue
+		}
+		c.Forwardable = c.Forwardable && e.Forwardable
+		c.GloballyReachable = c.GloballyReachable && e.GloballyReachable
+		c.ReservedByProtocol = c.ReservedByProtocol || e.ReservedByProtocol
+	}
+
+	return c
+}
+
+// Bogons returns the merged ranges never to be seen as source or destination
+// on the public Internet: the special-purpose blocks not globally reachable
+// and the multicast blocks 224.0.0.0/4 and ff00::/8.
+//
+// IPv6 space not yet allocated by IANA is not included, see GlobalUnicast.
+func Bogons() []IPRange {
+	return slices.Clone(bogons())
+}
+
+// GlobalUnicast returns the merged ranges of global unicast space:
+// 0.0.0.0/0 and 2000::/3 without the Bogons.
+func GlobalUnicast() []IPRange {
+	var out []IPRange
+	for _, s := range globalUnicast {
+		out = append(out, mustPrefix(s).Remove(bogons())...)
+	}
+	return out
+}
+
+// effective returns the part of r within the entry e,
+// without the more specific entries within e.
+func effective(e SpecialPurpose, r IPRange) []IPRange {
+	if e.Range.isDisjunct(r) {
+		return nil
+	}
+	if e.Range.first.Compare(r.first) > 0 {
+		r.first = e.Range.first
+	}
+	if e.Range.last.Compare(r.last) < 0 {
+		r.last = e.Range.last
+	}
+
+	var inner []IPRange
+	for _, x := range specialPurpose() {
+		if x.Range != e.Range && e.Range.covers(x.Range) {
+			inner = append(inner, x.Range)
+		}
+	}
+	return r.Remove(inner)
+}
+
+// mustPrefix is for the compiled-in tables only.
+func mustPrefix(s string) IPRange {
+	r, err := FromPrefix(netip.MustParsePrefix(s))
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
@@ -0,0 +1,173 @@
+package iprange_test
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestSpecialPurposeRegistry(t *testing.T) {
+	t.Parallel()
+	reg := iprange.SpecialPurposeRegistry()
+
+	var rs []iprange.IPRange
+	for _, e := range reg {
+		if _, ok := e.Range.Prefix(); !ok {
+			t.Errorf("SpecialPurposeRegistry, %v is no prefix", e.Range)
+		}
+		rs = append(rs, e.Range)
+	}
+
+	if !slices.IsSortedFunc(rs, func(a, b iprange.IPRange) int {
+		ll, rr, _, _ := iprange.Compare(a, b)
+		if ll != 0 {
+			return ll
+		}
+		return -rr
+	}) {
+		t.Errorf("SpecialPurposeRegistry is not sorted")
+	}
+
+	// returns a copy
+	reg[0].Name = "changed"
+	if iprange.SpecialPurposeRegistry()[0].Name == "changed" {
+		t.Errorf("SpecialPurposeRegistry, returned slice aliases the registry")
+	}
+}
+
+func TestClassifyAddr(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		addr               string
+		names              []string
+		forwardable        bool
+		globallyReachable  bool
+		reservedByProtocol bool
+	}{
+		{"8.8.8.8", nil, true, true, false},
+		{"10.1.2.3", []string{"Private-Use"}, true, false, false},
+		{"127.0.0.1", []string{"Loopback"}, false, false, true},
+		{"0.0.0.0", []string{"This network", "This host on this network"}, false, false, true},
+		{"192.0.0.9", []string{"IETF Protocol Assignments", "Port Control Protocol Anycast"}, true, true, false},
+		{"192.0.0.100", []string{"IETF Protocol Assignments"}, false, false, false},
+		{"255.255.255.255", []string{"Reserved", "Limited Broadcast"}, false, false, true},
+		{"2001:db8::1", []string{"Documentation"}, false, false, false},
+		{"2001::1", []string{"IETF Protocol Assignments", "TEREDO"}, true, true, false},
+		{"2001:1::1", []string{"IETF Protocol Assignments", "Port Control Protocol Anycast"}, true, true, false},
+		{"fe80::1", []string{"Link-Local Unicast"}, false, false, true},
+		{"2a00:1450::1", nil, true, true, false},
+	}
+
+	for _, tt := range tests {
+		c := iprange.Classify(mustParseAddr(tt.addr))
+
+		var names []string
+		for _, e := range c.Entries {
+			names = append(names, e.Name)
+		}
+		if !slices.Equal(names, tt.names) {
+			t.Errorf("Classify(%s), got entries %q, want %q", tt.addr, names, tt.names)
+		}
+		if c.Forwardable != tt.forwardable || c.GloballyReachable != tt.globallyReachable || c.ReservedByProtocol != tt.reservedByProtocol {
+			t.Errorf("Classify(%s), got flags %v %v %v, want %v %v %v", tt.addr,
+				c.Forwardable, c.GloballyReachable, c.ReservedByProtocol,
+				tt.forwardable, tt.globallyReachable, tt.reservedByProtocol)
+		}
+	}
+}
+
+func TestClassifyRange(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		rng                string
+		entries            int
+		forwardable        bool
+		globallyReachable  bool
+		reservedByProtocol bool
+	}{
+		// partly private
+		{"9.255.255.0-10.0.0.255", 1, true, false, false},
+		// only the global anycast addresses
+		{"192.0.0.9-192.0.0.10", 3, true, true, false},
+		// one more, not overridden
+		{"192.0.0.9-192.0.0.11", 3, false, false, false},
+		// all of IPv4
+		{"0.0.0.0/0", 24, false, false, true},
+		{"2001::/32", 2, true, true, false},
+	}
+
+	for _, tt := range tests {
+		c := iprange.Classify(mustFromString(tt.rng))
+		if len(c.Entries) != tt.entries {
+			t.Errorf("Classify(%s), got %d entries, want %d", tt.rng, len(c.Entries), tt.entries)
+		}
+		if c.Forwardable != tt.forwardable || c.GloballyReachable != tt.globallyReachable || c.ReservedByProtocol != tt.reservedByProtocol {
+			t.Errorf("Classify(%s), got flags %v %v %v, want %v %v %v", tt.rng,
+				c.Forwardable, c.GloballyReachable, c.ReservedByProtocol,
+				tt.forwardable, tt.globallyReachable, tt.reservedByProtocol)
+		}
+	}
+
+	if c := iprange.Classify(iprange.IPRange{}); len(c.Entries) != 0 || c.Forwardable || c.GloballyReachable {
+		t.Errorf("Classify(zero value), got %+v, want zero Classification", c)
+	}
+	if c := iprange.Classify(netip.Addr{}); len(c.Entries) != 0 || c.Forwardable {
+		t.Errorf("Classify(invalid addr), got %+v, want zero Classification", c)
+	}
+}
+
+func TestBogons(t *testing.T) {
+	t.Parallel()
+	bogons := iprange.Bogons()
+
+	if !slices.Equal(bogons, iprange.Merge(bogons)) {
+		t.Errorf("Bogons, not merged: %v", bogons)
+	}
+
+	contains := func(rs []iprange.IPRange, s string) bool {
+		a := mustParseAddr(s)
+		for _, r := range rs {
+			first, last := r.Addrs()
+			if first.Compare(a) <= 0 && a.Compare(last) <= 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, s := range []string{"0.1.2.3", "10.0.0.1", "192.0.0.8", "192.0.2.1", "224.0.0.1", "255.255.255.255", "::1", "fc00::1", "ff02::1", "2001:db8::1", "2001:1::4"} {
+		if !contains(bogons, s) {
+			t.Errorf("Bogons, missing %s", s)
+		}
+	}
+	for _, s := range []string{"8.8.8.8", "192.0.0.9", "192.31.196.1", "2001::1", "2002::1", "2a00::1"} {
+		if contains(bogons, s) {
+			t.Errorf("Bogons, unexpected %s", s)
+		}
+	}
+
+	global := iprange.GlobalUnicast()
+	for _, s := range []string{"8.8.8.8", "192.0.0.9", "2001::1", "2a00::1"} {
+		if !contains(global, s) {
+			t.Errorf("GlobalUnicast, missing %s", s)
+		}
+	}
+	for _, s := range []string{"10.0.0.1", "224.0.0.1", "fc00::1", "4000::1", "2001:db8::1"} {
+		if contains(global, s) {
+			t.Errorf("GlobalUnicast, unexpected %s", s)
+		}
+	}
+
+	// disjoint, IPv4 complement of each other
+	var v4 []iprange.IPRange
+	for _, r := range slices.Concat(bogons, global) {
+		if first, _ := r.Addrs(); first.Is4() {
+			v4 = append(v4, r)
+		}
+	}
+	if got := iprange.Merge(v4); len(got) != 1 || got[0] != mustFromString("0.0.0.0/0") {
+		t.Errorf("Bogons and GlobalUnicast, IPv4 union got %v, want 0.0.0.0/0", got)
+	}
+}
@@ -0,0 +1,51 @@
+// Package rangetext implements the relaxed range notation of prose and
+// registry data, "first - last" with blanks around the hyphen, shared by
+// the parsers and scanners of this module.
+package rangetext
+
+import (
+	"strings"
+
+	"github.com/gaissmai/iprange"
+)
+
+// enDash is accepted as hyphen, common in typeset text.
+const enDash = "\u2013"
+
+// Separator returns the index behind the range separator starting at b[i:],
+// a hyphen or en dash with optional blanks around it.
+// It returns false if there is no separator at i.
+func Separator(b []byte, i int) (int, bool) {
+	i = skipBlanks(b, i)
+	switch {
+	case i < len(b) && b[i] == '-':
+		i++
+	case strings.HasPrefix(string(b[i:]), enDash):
+		i += len(enDash)
+	default:
+		return 0, false
+	}
+	return skipBlanks(b, i), true
+}
+
+// Parse is like iprange.FromString, but accepts the relaxed separator
+// of a range, e.g. "193.0.0.0 - 193.0.7.255". Leading and trailing
+// whitespace is ignored.
+func Parse(s string) (iprange.IPRange, error) {
+	s = strings.TrimSpace(s)
+
+	// end of the first address, then the separator
+	if i := strings.IndexAny(s, " \t-"+enDash); i >= 0 {
+		if j, ok := Separator([]byte(s), i); ok {
+			s = s[:i] + "-" + s[j:]
+		}
+	}
+	return iprange.FromString(s)
+}
+
+func skipBlanks(b []byte, i int) int {
+	for i < len(b) && (b[i] == ' ' || b[i] == '\t') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,67 @@
+package rangetext
+
+import (
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestSeparator(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		i    int
+		want int
+		ok   bool
+	}{
+		{"a-b", 1, 2, true},
+		{"a - b", 1, 4, true},
+		{"a \t-\t b", 1, 6, true},
+		{"a \u2013 b", 1, 6, true},
+		{"a b", 1, 0, false},
+		{"a", 1, 0, false},
+		{"a -", 1, 3, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := Separator([]byte(tt.in), tt.i)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Separator(%q, %d), got %d %v, want %d %v", tt.in, tt.i, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"10.0.0.1", "10.0.0.1/32", true},
+		{" 10.0.0.0/24 ", "10.0.0.0/24", true},
+		{"10.0.0.1-10.0.0.9", "10.0.0.1-10.0.0.9", true},
+		{"193.0.0.0 - 193.0.7.255", "193.0.0.0/21", true},
+		{"10.0.0.1\t-  10.0.0.9", "10.0.0.1-10.0.0.9", true},
+		{"10.0.0.1 \u2013 10.0.0.9", "10.0.0.1-10.0.0.9", true},
+		{"2001:db8:: - 2001:db8::ff", "2001:db8::/120", true},
+		{"10.0.0.1 10.0.0.9", "", false},
+		{"10.0.0.1 -", "", false},
+		{"10.0.0.9 - 10.0.0.1", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("Parse(%q), got err %v, want ok %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok {
+			want, _ := iprange.FromString(tt.want)
+			if got != want {
+				t.Errorf("Parse(%q), got %v, want %v", tt.in, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package u128 implements the 128-bit unsigned arithmetic on IP addresses
+// needed for address offsets and counts.
+package u128
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+	"net/netip"
+)
+
+// Uint128 is a 128-bit unsigned integer.
+type Uint128 struct {
+	Hi uint64
+	Lo uint64
+}
+
+// Max is the largest Uint128 value.
+var Max = Uint128{^uint64(0), ^uint64(0)}
+
+// From returns the address as Uint128, IPv4 addresses in the low 32 bits.
+func From(a netip.Addr) Uint128 {
+	if a.Is4() {
+		b := a.As4()
+		return Uint128{0, uint64(b[0])<<24 | uint64(b[1])<<16 | uint64(b[2])<<8 | uint64(b[3])}
+	}
+	b := a.As16()
+	return Uint128{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])}
+}
+
+// From64 returns v as Uint128.
+func From64(v uint64) Uint128 {
+	return Uint128{0, v}
+}
+
+// FromBig returns v as Uint128, ok is false if v is negative or overflows.
+func FromBig(v *big.Int) (u Uint128, ok bool) {
+	if v.Sign() < 0 || v.BitLen() > 128 {
+		return u, false
+	}
+	var b [16]byte
+	v.FillBytes(b[:])
+	return Uint128{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])}, true
+}
+
+// Addr returns u as address, as IPv4 address if is4.
+// The high bits are truncated for IPv4.
+func (u Uint128) Addr(is4 bool) netip.Addr {
+	if is4 {
+		return netip.AddrFrom4([4]byte{byte(u.Lo >> 24), byte(u.Lo >> 16), byte(u.Lo >> 8), byte(u.Lo)})
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], u.Hi)
+	binary.BigEndian.PutUint64(b[8:], u.Lo)
+	return netip.AddrFrom16(b)
+}
+
+// Add returns u+v, carry is true on overflow.
+func (u Uint128) Add(v Uint128) (sum Uint128, carry bool) {
+	var c uint64
+	sum.Lo, c = bits.Add64(u.Lo, v.Lo, 0)
+	sum.Hi, c = bits.Add64(u.Hi, v.Hi, c)
+	return sum, c != 0
+}
+
+// Sub returns u-v, borrow is true on underflow.
+func (u Uint128) Sub(v Uint128) (diff Uint128, borrow bool) {
+	var b uint64
+	diff.Lo, b = bits.Sub64(u.Lo, v.Lo, 0)
+	diff.Hi, b = bits.Sub64(u.Hi, v.Hi, b)
+	return diff, b != 0
+}
+
+// Compare returns -1, 0 or +1.
+func (u Uint128) Compare(v Uint128) int {
+	switch {
+	case u.Hi < v.Hi:
+		return -1
+	case u.Hi > v.Hi:
+		return 1
+	case u.Lo < v.Lo:
+		return -1
+	case u.Lo > v.Lo:
+		return 1
+	}
+	return 0
+}
+
+// IsZero reports whether u is zero.
+func (u Uint128) IsZero() bool {
+	return u == Uint128{}
+}
+
+// Uint64 returns u as uint64, ok is false if u overflows.
+func (u Uint128) Uint64() (v uint64, ok bool) {
+	return u.Lo, u.Hi == 0
+}
+
+// Big returns u as big.Int.
+func (u Uint128) Big() *big.Int {
+	hi := new(big.Int).SetUint64(u.Hi)
+	return hi.Lsh(hi, 64).Or(hi, new(big.Int).SetUint64(u.Lo))
+}
@@ -0,0 +1,76 @@
+package u128
+
+import (
+	"math/big"
+	"net/netip"
+	"testing"
+)
+
+func TestFromAddr(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		addr string
+		want Uint128
+	}{
+		{"0.0.0.0", Uint128{}},
+		{"1.2.3.4", Uint128{0, 0x01020304}},
+		{"255.255.255.255", Uint128{0, 0xffffffff}},
+		{"::", Uint128{}},
+		{"2001:db8::1", Uint128{0x20010db800000000, 1}},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", Max},
+	}
+
+	for _, tt := range tests {
+		a := netip.MustParseAddr(tt.addr)
+		got := From(a)
+		if got != tt.want {
+			t.Errorf("From(%s), got %v, want %v", tt.addr, got, tt.want)
+		}
+		if back := got.Addr(a.Is4()); back != a {
+			t.Errorf("Addr(%v), got %s, want %s", got, back, a)
+		}
+	}
+}
+
+func TestArith(t *testing.T) {
+	t.Parallel()
+
+	sum, carry := Uint128{0, ^uint64(0)}.Add(From64(1))
+	if sum != (Uint128{1, 0}) || carry {
+		t.Errorf("Add, got %v %v, want {1 0} false", sum, carry)
+	}
+	if _, carry = Max.Add(From64(1)); !carry {
+		t.Errorf("Add, expected carry")
+	}
+
+	diff, borrow := Uint128{1, 0}.Sub(From64(1))
+	if diff != (Uint128{0, ^uint64(0)}) || borrow {
+		t.Errorf("Sub, got %v %v", diff, borrow)
+	}
+	if _, borrow = From64(0).Sub(From64(1)); !borrow {
+		t.Errorf("Sub, expected borrow")
+	}
+
+	if From64(1).Compare(Uint128{1, 0}) != -1 || Max.Compare(Max) != 0 || Max.Compare(From64(0)) != 1 {
+		t.Errorf("Compare, unexpected result")
+	}
+}
+
+func TestBig(t *testing.T) {
+	t.Parallel()
+	for _, u := range []Uint128{{}, From64(42), {1, 0}, Max} {
+		b := u.Big()
+		back, ok := FromBig(b)
+		if !ok || back != u {
+			t.Errorf("FromBig(%s), got %v %v, want %v", b, back, ok, u)
+		}
+	}
+
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 128)
+	if _, ok := FromBig(tooBig); ok {
+		t.Errorf("FromBig(2^128), expected not ok")
+	}
+	if _, ok := FromBig(big.NewInt(-1)); ok {
+		t.Errorf("FromBig(-1), expected not ok")
+	}
+}
@@ -0,0 +1,230 @@
+// Package ipam allocates prefixes from pools of arbitrary address ranges.
+//
+// An Allocator hands out the next free aligned prefix of a requested length,
+// reserves specific prefixes and releases them again. The free space is
+// the pools without the allocated prefixes, see iprange.IPRange.Remove.
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"github.com/gaissmai/iprange"
+)
+
+// ErrExhausted is returned if no free prefix of the requested length is left.
+var ErrExhausted = errors.New("no free prefix of requested length")
+
+// Strategy selects the free block a prefix is allocated from.
+type Strategy int
+
+const (
+	// FirstFit allocates the free prefix with the lowest address.
+	FirstFit Strategy = iota
+
+	// BestFit allocates from the smallest free aligned block the prefix fits in,
+	// keeping larger blocks intact. Ties are broken by the lowest address.
+	BestFit
+)
+
+// State is the serializable state of an Allocator, the ranges marshal
+// as text, e.g. with encoding/json:
+//
+//	{"Pools":["10.0.0.0-10.0.3.255"],"Allocated":["10.0.0.0/24"]}
+type State struct {
+	Pools     []iprange.IPRange
+	Allocated []iprange.IPRange
+}
+
+// Allocator allocates prefixes from pools.
+// It is safe for concurrent use.
+type Allocator struct {
+	strategy Strategy
+
+	mu        sync.Mutex
+	pools     []iprange.IPRange
+	free      []iprange.IPRange
+	allocated map[netip.Prefix]struct{}
+}
+
+// New returns an Allocator for the pools, IPv4 and IPv6 may be mixed.
+// The pools are merged, see iprange.Merge.
+func New(pools []iprange.IPRange, strategy Strategy) *Allocator {
+	merged := iprange.Merge(pools)
+	return &Allocator{
+		strategy:  strategy,
+		pools:     merged,
+		free:      slices.Clone(merged),
+		allocated: make(map[netip.Prefix]struct{}),
+	}
+}
+
+// Restore returns an Allocator with the state of a Snapshot.
+// It returns an error if an allocated range is no prefix,
+// is outside of the pools or overlaps another.
+func Restore(s State, strategy Strategy) (*Allocator, error) {
+	a := New(s.Pools, strategy)
+	for _, r := range s.Allocated {
+		p, ok := r.Prefix()
+		if !ok {
+			return nil, fmt.Errorf("allocated range %s is no prefix", r)
+		}
+		if err := a.Reserve(p); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Snapshot returns the state of the allocator, the allocated prefixes sorted.
+func (a *Allocator) Snapshot() State {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := State{Pools: slices.Clone(a.pools)}
+	for _, p := range a.sortedAllocated() {
+		r, _ := iprange.FromPrefix(p)
+		s.Allocated = append(s.Allocated, r)
+	}
+	return s
+}
+
+// Allocate4 allocates a free IPv4 prefix with the length bits.
+func (a *Allocator) Allocate4(bits int) (netip.Prefix, error) {
+	return a.allocate(bits, true)
+}
+
+// Allocate6 allocates a free IPv6 prefix with the length bits.
+func (a *Allocator) Allocate6(bits int) (netip.Prefix, error) {
+	return a.allocate(bits, false)
+}
+
+func (a *Allocator) allocate(bits int, is4 bool) (netip.Prefix, error) {
+	maxBits := 128
+	if is4 {
+		maxBits = 32
+	}
+	if bits < 0 || bits > maxBits {
+		return netip.Prefix{}, fmt.Errorf("invalid prefix length %d", bits)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// The minimal prefixes of the free ranges contain all free aligned
+	// blocks, a block fits if it is not longer than bits.
+	var block netip.Prefix
+	for _, r := range a.free {
+		if first, _ := r.Addrs(); first.Is4() != is4 {
+			continue
+		}
+		for p := range r.Prefixes() {
+			if p.Bits() > bits {
+				continue
+			}
+			if !block.IsValid() || a.strategy == BestFit && p.Bits() > block.Bits() {
+				block = p
+			}
+		}
+		if block.IsValid() && a.strategy == FirstFit {
+			break
+		}
+	}
+
+	if !block.IsValid() {
+		return netip.Prefix{}, ErrExhausted
+	}
+
+	p := netip.PrefixFrom(block.Addr(), bits)
+	a.take(p)
+	return p, nil
+}
+
+// Reserve allocates the specific prefix p.
+// It returns an error if p is not entirely free.
+func (a *Allocator) Reserve(p netip.Prefix) error {
+	r, err := iprange.FromPrefix(p)
+	if err != nil {
+		return err
+	}
+	if p != p.Masked() {
+		return fmt.Errorf("prefix %s has host bits set", p)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !covered(a.free, r) {
+		return fmt.Errorf("prefix %s is not free", p)
+	}
+	a.take(p)
+	return nil
+}
+
+// Release returns the allocated prefix p to the free space.
+// It returns an error if p was not allocated.
+func (a *Allocator) Release(p netip.Prefix) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.allocated[p]; !ok {
+		return fmt.Errorf("prefix %s is not allocated", p)
+	}
+	delete(a.allocated, p)
+
+	r, _ := iprange.FromPrefix(p)
+	a.free = iprange.Merge(append(a.free, r))
+	return nil
+}
+
+// Allocated returns the allocated prefixes, sorted.
+func (a *Allocator) Allocated() []netip.Prefix {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.sortedAllocated()
+}
+
+// Free returns the free ranges, sorted.
+func (a *Allocator) Free() []iprange.IPRange {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.free)
+}
+
+// take removes the free prefix p from the free space.
+func (a *Allocator) take(p netip.Prefix) {
+	r, _ := iprange.FromPrefix(p)
+
+	var free []iprange.IPRange
+	for _, f := range a.free {
+		free = append(free, f.Remove([]iprange.IPRange{r})...)
+	}
+	a.free = free
+	a.allocated[p] = struct{}{}
+}
+
+func (a *Allocator) sortedAllocated() []netip.Prefix {
+	out := make([]netip.Prefix, 0, len(a.allocated))
+	for p := range a.allocated {
+		out = append(out, p)
+	}
+	slices.SortFunc(out, func(x, y netip.Prefix) int {
+		return x.Addr().Compare(y.Addr())
+	})
+	return out
+}
+
+// covered reports whether r is within one of the sorted disjoint ranges.
+func covered(rs []iprange.IPRange, r iprange.IPRange) bool {
+	first, last := r.Addrs()
+	for _, f := range rs {
+		fFirst, fLast := f.Addrs()
+		if fFirst.Compare(first) <= 0 && last.Compare(fLast) <= 0 {
+			return true
+		}
+	}
+	return false
+}
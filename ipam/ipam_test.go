@@ -0,0 +1,201 @@
+package ipam_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/ipam"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestAllocateFirstFit(t *testing.T) {
+	t.Parallel()
+	// unaligned pool, the first aligned /24 is 10.0.1.0/24
+	a := ipam.New([]iprange.IPRange{mustFromString("10.0.0.128-10.0.3.255")}, ipam.FirstFit)
+
+	var got []netip.Prefix
+	for range 3 {
+		p, err := a.Allocate4(24)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p)
+	}
+
+	want := []netip.Prefix{
+		netip.MustParsePrefix("10.0.1.0/24"),
+		netip.MustParsePrefix("10.0.2.0/24"),
+		netip.MustParsePrefix("10.0.3.0/24"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Allocate4(24), got %v, want %v", got, want)
+	}
+
+	if _, err := a.Allocate4(24); !errors.Is(err, ipam.ErrExhausted) {
+		t.Errorf("Allocate4(24), got %v, want ErrExhausted", err)
+	}
+
+	// the rest of the pool
+	if p, err := a.Allocate4(25); err != nil || p != netip.MustParsePrefix("10.0.0.128/25") {
+		t.Errorf("Allocate4(25), got %v %v, want 10.0.0.128/25", p, err)
+	}
+	if got := a.Free(); len(got) != 0 {
+		t.Errorf("Free, got %v, want empty", got)
+	}
+}
+
+func TestAllocateBestFit(t *testing.T) {
+	t.Parallel()
+	pools := []iprange.IPRange{
+		mustFromString("10.0.0.0/16"),
+		mustFromString("10.1.0.0/24"),
+		mustFromString("2001:db8::/56"),
+	}
+
+	best := ipam.New(pools, ipam.BestFit)
+	if p, _ := best.Allocate4(26); p != netip.MustParsePrefix("10.1.0.0/26") {
+		t.Errorf("BestFit Allocate4(26), got %v, want 10.1.0.0/26", p)
+	}
+	// the remaining 10.1.0.64/26 is the smallest block, not the /25
+	if p, _ := best.Allocate4(26); p != netip.MustParsePrefix("10.1.0.64/26") {
+		t.Errorf("BestFit Allocate4(26), got %v, want 10.1.0.64/26", p)
+	}
+
+	first := ipam.New(pools, ipam.FirstFit)
+	if p, _ := first.Allocate4(26); p != netip.MustParsePrefix("10.0.0.0/26") {
+		t.Errorf("FirstFit Allocate4(26), got %v, want 10.0.0.0/26", p)
+	}
+
+	if p, err := best.Allocate6(64); err != nil || p != netip.MustParsePrefix("2001:db8::/64") {
+		t.Errorf("Allocate6(64), got %v %v, want 2001:db8::/64", p, err)
+	}
+	if _, err := best.Allocate6(48); !errors.Is(err, ipam.ErrExhausted) {
+		t.Errorf("Allocate6(48), got %v, want ErrExhausted", err)
+	}
+	for _, bits := range []int{-1, 33} {
+		if _, err := best.Allocate4(bits); err == nil || errors.Is(err, ipam.ErrExhausted) {
+			t.Errorf("Allocate4(%d), got %v, want invalid length error", bits, err)
+		}
+	}
+}
+
+func TestReserveRelease(t *testing.T) {
+	t.Parallel()
+	a := ipam.New([]iprange.IPRange{mustFromString("10.0.0.0/22")}, ipam.FirstFit)
+
+	if err := a.Reserve(netip.MustParsePrefix("10.0.0.0/24")); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"10.0.0.0/25", "10.0.4.0/24", "10.0.0.0/21", "10.0.1.1/24"} {
+		if err := a.Reserve(netip.MustParsePrefix(s)); err == nil {
+			t.Errorf("Reserve(%s), expected error", s)
+		}
+	}
+
+	if p, _ := a.Allocate4(24); p != netip.MustParsePrefix("10.0.1.0/24") {
+		t.Errorf("Allocate4(24), got %v, want 10.0.1.0/24", p)
+	}
+
+	if err := a.Release(netip.MustParsePrefix("10.0.0.0/24")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Release(netip.MustParsePrefix("10.0.0.0/24")); err == nil {
+		t.Errorf("Release twice, expected error")
+	}
+	if err := a.Release(netip.MustParsePrefix("10.0.1.0/25")); err == nil {
+		t.Errorf("Release of part of an allocation, expected error")
+	}
+
+	want := []iprange.IPRange{mustFromString("10.0.0.0/24"), mustFromString("10.0.2.0/23")}
+	if got := a.Free(); !slices.Equal(got, want) {
+		t.Errorf("Free, got %v, want %v", got, want)
+	}
+	if got := a.Allocated(); !slices.Equal(got, []netip.Prefix{netip.MustParsePrefix("10.0.1.0/24")}) {
+		t.Errorf("Allocated, got %v", got)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	t.Parallel()
+	a := ipam.New([]iprange.IPRange{mustFromString("10.0.0.0/22"), mustFromString("2001:db8::/48")}, ipam.FirstFit)
+	_, _ = a.Allocate4(24)
+	_, _ = a.Allocate4(23)
+	_, _ = a.Allocate6(64)
+
+	data, err := json.Marshal(a.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"Pools":["10.0.0.0/22","2001:db8::/48"],"Allocated":["10.0.0.0/24","10.0.2.0/23","2001:db8::/64"]}`
+	if string(data) != want {
+		t.Errorf("Snapshot, got %s, want %s", data, want)
+	}
+
+	var s ipam.State
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ipam.Restore(s, ipam.FirstFit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(b.Allocated(), a.Allocated()) || !slices.Equal(b.Free(), a.Free()) {
+		t.Errorf("Restore, got %v %v, want %v %v", b.Allocated(), b.Free(), a.Allocated(), a.Free())
+	}
+
+	bad := []ipam.State{
+		{Pools: s.Pools, Allocated: []iprange.IPRange{mustFromString("10.0.0.1-10.0.0.5")}},
+		{Pools: s.Pools, Allocated: []iprange.IPRange{mustFromString("10.0.4.0/24")}},
+		{Pools: s.Pools, Allocated: []iprange.IPRange{mustFromString("10.0.0.0/24"), mustFromString("10.0.0.0/25")}},
+	}
+	for _, s := range bad {
+		if _, err := ipam.Restore(s, ipam.FirstFit); err == nil {
+			t.Errorf("Restore(%v), expected error", s)
+		}
+	}
+}
+
+func TestConcurrentAllocate(t *testing.T) {
+	t.Parallel()
+	a := ipam.New([]iprange.IPRange{mustFromString("10.0.0.0/16")}, ipam.BestFit)
+
+	var mu sync.Mutex
+	seen := make(map[netip.Prefix]bool)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 32 {
+				p, err := a.Allocate4(24)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[p] {
+					t.Errorf("Allocate4(24), %s allocated twice", p)
+				}
+				seen[p] = true
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	if len(seen) != 256 || len(a.Free()) != 0 {
+		t.Errorf("concurrent Allocate4, got %d prefixes, free %v", len(seen), a.Free())
+	}
+}
@@ -0,0 +1,142 @@
+// Package ipgrep filters text lines by the IP addresses they contain,
+// in the style of grepcidr.
+//
+// Every IPv4 and IPv6 address in a line is extracted and looked up in a set
+// of ranges, loaded e.g. with iprange.FromString or iprange.ReadList.
+// Unlike grepcidr, the set may hold arbitrary ranges of both address families.
+package ipgrep
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/netip"
+	"slices"
+	"sort"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/ipscan"
+)
+
+// Set is an immutable set of IP addresses for fast membership tests.
+type Set struct {
+	ranges []iprange.IPRange // merged, sorted and disjoint
+}
+
+// NewSet returns the set of all addresses in rs, the input may be unsorted and overlapping.
+func NewSet(rs []iprange.IPRange) *Set {
+	return &Set{ranges: iprange.Merge(rs)}
+}
+
+// Ranges returns the merged ranges of the set.
+func (s *Set) Ranges() []iprange.IPRange {
+	return slices.Clone(s.ranges)
+}
+
+// Contains reports whether the address a is in the set.
+func (s *Set) Contains(a netip.Addr) bool {
+	// first range not ending before a
+	i := sort.Search(len(s.ranges), func(i int) bool {
+		_, last := s.ranges[i].Addrs()
+		return !last.Less(a)
+	})
+	if i == len(s.ranges) {
+		return false
+	}
+
+	first, _ := s.ranges[i].Addrs()
+	return first.Compare(a) <= 0
+}
+
+// Addrs returns an iterator over all IPv4 and IPv6 addresses in line, in order of appearance.
+//
+// Addresses are recognized as in ipscan.Addrs: tokens delimited by non-word
+// characters, with trailing punctuation and ports like "10.0.0.1:443" stripped.
+// IPv6 zones are not part of the address.
+func Addrs(line []byte) iter.Seq[netip.Addr] {
+	return ipscan.Addrs(line)
+}
+
+// Options control the output of Grep.
+type Options struct {
+	// Invert selects the lines without any address in the set,
+	// with OnlyMatching the addresses not in the set.
+	Invert bool
+
+	// Count suppresses the output of lines, only the number of
+	// selected lines is written.
+	Count bool
+
+	// OnlyMatching writes each selected address on its own line,
+	// instead of the whole line.
+	OnlyMatching bool
+
+	// Label, if not empty, prefixes every output line with "label:".
+	Label string
+}
+
+// Grep reads lines from r and writes the lines with an address in the set s to w.
+// It returns the number of selected lines, with OnlyMatching the number of selected addresses.
+func Grep(w io.Writer, r io.Reader, s *Set, opts Options) (n int, err error) {
+	if s == nil {
+		return 0, errors.New("nil set")
+	}
+
+	prefix := ""
+	if opts.Label != "" {
+		prefix = opts.Label + ":"
+	}
+
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+
+	for {
+		line, readErr := br.ReadBytes('\n')
+		if len(line) > 0 {
+			text := bytes.TrimRight(line, "\r\n")
+
+			if opts.OnlyMatching {
+				for a := range Addrs(text) {
+					if s.Contains(a) == opts.Invert {
+						continue
+					}
+					n++
+					if !opts.Count {
+						fmt.Fprintf(bw, "%s%s\n", prefix, a)
+					}
+				}
+			} else if matchLine(text, s) != opts.Invert {
+				n++
+				if !opts.Count {
+					fmt.Fprintf(bw, "%s%s\n", prefix, text)
+				}
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return n, readErr
+		}
+	}
+
+	if opts.Count {
+		fmt.Fprintf(bw, "%s%d\n", prefix, n)
+	}
+
+	return n, bw.Flush()
+}
+
+// matchLine reports whether any address in line is in the set.
+func matchLine(line []byte, s *Set) bool {
+	for a := range Addrs(line) {
+		if s.Contains(a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package ipgrep_test
+
+import (
+	"bytes"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/ipgrep"
+)
+
+func mustSet(ss ...string) *ipgrep.Set {
+	var rs []iprange.IPRange
+	for _, s := range ss {
+		r, err := iprange.FromString(s)
+		if err != nil {
+			panic(err)
+		}
+		rs = append(rs, r)
+	}
+	return ipgrep.NewSet(rs)
+}
+
+func TestAddrs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"no addresses here, deadbeef 12:34:56 1.2.3", nil},
+		{"Accepted password from 10.0.0.1 port 22", []string{"10.0.0.1"}},
+		{"connect from 10.0.0.1.", []string{"10.0.0.1"}},
+		{"GET / 10.0.0.1:443 -> [2001:db8::1]:8443", []string{"10.0.0.1", "2001:db8::1"}},
+		{"src=::1,dst=::ffff:1.2.3.4;", []string{"::1", "::ffff:1.2.3.4"}},
+		{"fe80::1%eth0 via 2001:db8::", []string{"fe80::1", "2001:db8::"}},
+		{"host abc10.0.0.7 x10.0.0.8 10.0.0.9x and ip 1.2.3.4.5", nil},
+		{"range 10.0.0.1-10.0.0.9 and net 10.0.1.0/24", []string{"10.0.0.1", "10.0.0.9", "10.0.1.0"}},
+		{"a :: b ::", nil},
+		{"src:10.0.0.1 dst:10.0.0.2", []string{"10.0.0.1", "10.0.0.2"}},
+		{"id:10.0.0.1 fwd:10.0.0.3", []string{"10.0.0.1", "10.0.0.3"}},
+		{"src:2001:db8::1 dst:[2001:db8::2]:443", []string{"2001:db8::1", "2001:db8::2"}},
+		{"addr: 2001:db8::2: up", []string{"2001:db8::2"}},
+		{"256.1.1.1 300.0.0.1 1.1.1.1", []string{"1.1.1.1"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for a := range ipgrep.Addrs([]byte(tt.line)) {
+			got = append(got, a.String())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Addrs(%q), got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSetContains(t *testing.T) {
+	t.Parallel()
+	set := mustSet("10.0.0.3-10.0.0.9", "192.168.0.0/16", "2001:db8::/32", "10.0.0.10")
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.0.0.2", false},
+		{"10.0.0.3", true},
+		{"10.0.0.10", true},
+		{"10.0.0.11", false},
+		{"192.168.255.255", true},
+		{"255.255.255.255", false},
+		{"::1", false},
+		{"2001:db8:ffff::1", true},
+		{"::ffff:10.0.0.5", false},
+	}
+
+	for _, tt := range tests {
+		if got := set.Contains(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("Contains(%s), got %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+
+	if got := len(set.Ranges()); got != 3 {
+		t.Errorf("Ranges(), got %d ranges, want 3", got)
+	}
+}
+
+func TestGrep(t *testing.T) {
+	t.Parallel()
+	input := "line 1 from 10.0.0.5\r\n" +
+		"line 2 from 172.16.0.1\n" +
+		"line 3 without address\n" +
+		"line 4 from 172.16.0.1 and 2001:db8::1\n" +
+		"line 5 from 10.0.0.200" // no trailing newline
+
+	set := mustSet("10.0.0.0-10.0.0.99", "2001:db8::/32")
+
+	tests := []struct {
+		opts ipgrep.Options
+		want string
+		n    int
+	}{
+		{
+			opts: ipgrep.Options{},
+			want: "line 1 from 10.0.0.5\nline 4 from 172.16.0.1 and 2001:db8::1\n",
+			n:    2,
+		},
+		{
+			opts: ipgrep.Options{Invert: true},
+			want: "line 2 from 172.16.0.1\nline 3 without address\nline 5 from 10.0.0.200\n",
+			n:    3,
+		},
+		{
+			opts: ipgrep.Options{Count: true},
+			want: "2\n",
+			n:    2,
+		},
+		{
+			opts: ipgrep.Options{OnlyMatching: true, Label: "log"},
+			want: "log:10.0.0.5\nlog:2001:db8::1\n",
+			n:    2,
+		},
+		{
+			opts: ipgrep.Options{OnlyMatching: true, Invert: true},
+			want: "172.16.0.1\n172.16.0.1\n10.0.0.200\n",
+			n:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		n, err := ipgrep.Grep(&buf, strings.NewReader(input), set, tt.opts)
+		if err != nil {
+			t.Fatalf("Grep(%+v) failed: %v", tt.opts, err)
+		}
+		if n != tt.n {
+			t.Errorf("Grep(%+v), got n=%d, want %d", tt.opts, n, tt.n)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Grep(%+v)\ngot:\n%s\nwant:\n%s", tt.opts, buf.String(), tt.want)
+		}
+	}
+
+	if _, err := ipgrep.Grep(&bytes.Buffer{}, strings.NewReader(input), nil, ipgrep.Options{}); err == nil {
+		t.Errorf("Grep with nil set, expected error, got nil")
+	}
+}
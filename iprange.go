@@ -134,12 +134,23 @@ func (r IPRange) String() string {
 		return "invalid IPRange"
 	}
 
-	pfx, ok := r.Prefix()
-	if !ok {
-		return fmt.Sprintf("%s-%s", r.first, r.last)
+	var buf [maxTextLen]byte
+	return string(r.appendTo(buf[:0]))
+}
+
+// maxTextLen is the maximum length of the text representation of an IPRange,
+// two IPv4-mapped IPv6 addresses in dotted form separated by a hyphen.
+const maxTextLen = 2*len("ffff:ffff:ffff:ffff:ffff:ffff:255.255.255.255") + 1
+
+// appendTo appends the text representation of the valid range r to b.
+func (r IPRange) appendTo(b []byte) []byte {
+	if pfx, ok := r.Prefix(); ok {
+		return pfx.AppendTo(b)
 	}
 
-	return pfx.String()
+	b = r.first.AppendTo(b)
+	b = append(b, '-')
+	return r.last.AppendTo(b)
 }
 
 // Prefixes returns a standard iterator yielding the minimal set of netip.Prefix values
@@ -271,14 +282,25 @@ func Compare(a, b IPRange) (ll int, rr int, lr int, rl int) {
 	return
 }
 
+// AppendText implements encoding.TextAppender.
+// It appends the text representation of r, as returned by String, to b
+// and returns the extended buffer.
+// If the range is invalid or uninitialized, b is returned unchanged.
+func (r IPRange) AppendText(b []byte) ([]byte, error) {
+	if !r.IsValid() {
+		return b, nil
+	}
+	return r.appendTo(b), nil
+}
+
 // MarshalText implements encoding.TextMarshaler.
-// It returns the text representation of the range using String().
+// It returns the text representation of the range as returned by String.
 // If the range is invalid or uninitialized, it returns nil.
 func (r IPRange) MarshalText() ([]byte, error) {
 	if !r.IsValid() {
 		return nil, nil
 	}
-	return []byte(r.String()), nil
+	return r.AppendText(make([]byte, 0, maxTextLen))
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler.
@@ -307,31 +329,66 @@ func (r *IPRange) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// Binary encoding format identifiers, see AppendBinary.
+const (
+	binaryVersion1 = 1
+
+	familyIPv4 = 4
+	familyIPv6 = 6
+)
+
+// AppendBinary implements encoding.BinaryAppender.
+// It appends a versioned binary encoding of r to b and returns the extended buffer.
+//
+// The encoding starts with a version byte and a family byte (4 or 6),
+// followed by the boundary addresses as raw bytes:
+//
+//	[version][family][first][last]
+//
+// That is 10 bytes for IPv4 and 34 bytes for IPv6, IPv4-mapped IPv6
+// addresses keep their 16-byte form. If r is invalid, b is returned unchanged.
+func (r IPRange) AppendBinary(b []byte) ([]byte, error) {
+	if !r.IsValid() {
+		return b, nil
+	}
+
+	family := byte(familyIPv4)
+	if r.first.Is6() {
+		family = familyIPv6
+	}
+
+	b = append(b, binaryVersion1, family)
+	b = appendAddr(b, r.first)
+	b = appendAddr(b, r.last)
+
+	return b, nil
+}
+
 // MarshalBinary implements encoding.BinaryMarshaler.
-// It encodes the boundary addresses consecutively as raw bytes.
-// (8 bytes for IPv4, 32 bytes for IPv6). It returns nil if the range is invalid.
+// It returns the versioned encoding described in AppendBinary.
+// It returns nil if the range is invalid.
 func (r IPRange) MarshalBinary() ([]byte, error) {
 	if !r.IsValid() {
 		return nil, nil
 	}
 
-	size := 8
+	size := 2 + 8
 	if r.first.Is6() {
-		size = 32
+		size = 2 + 32
 	}
 
-	b := make([]byte, 0, size)
-	b = append(b, r.first.AsSlice()...)
-	b = append(b, r.last.AsSlice()...)
-
-	return b, nil
+	return r.AppendBinary(make([]byte, 0, size))
 }
 
 // UnmarshalBinary implements encoding.BinaryUnmarshaler.
-// It reconstructs the IPRange from bytes generated by MarshalBinary.
+// It reconstructs the IPRange from bytes generated by MarshalBinary or AppendBinary.
+//
+// For backward compatibility it also accepts the legacy unversioned form,
+// the boundary addresses as raw bytes (8 bytes for IPv4, 32 bytes for IPv6).
+//
 // It returns an error if the receiver is nil, not a zero value,
-// if the byte slice length is not 8 or 32, or if the decoded last IP
-// address is less than the first IP address.
+// if the version or family is unknown, if the byte slice length does not
+// match the family, or if the decoded last IP address is less than the first IP address.
 func (r *IPRange) UnmarshalBinary(data []byte) error {
 	if r == nil {
 		return errors.New("UnmarshalBinary on nil receiver")
@@ -346,11 +403,52 @@ func (r *IPRange) UnmarshalBinary(data []byte) error {
 		return nil
 	}
 
-	// Must be exactly 8 bytes (two 4-byte IPv4 addresses) or 32 bytes (two 16-byte IPv6 addresses).
-	if n != 8 && n != 32 {
+	// Legacy form: exactly 8 bytes (two 4-byte IPv4 addresses)
+	// or 32 bytes (two 16-byte IPv6 addresses), no header.
+	if n == 8 || n == 32 {
+		return r.unmarshalAddrs(data)
+	}
+
+	if n < 2 {
 		return errors.New("unexpected slice size")
 	}
 
+	if version := data[0]; version != binaryVersion1 {
+		return fmt.Errorf("unsupported binary version %d", version)
+	}
+
+	family, addrs := data[1], data[2:]
+	switch family {
+	case familyIPv4:
+		if len(addrs) != 8 {
+			return errors.New("unexpected slice size")
+		}
+	case familyIPv6:
+		if len(addrs) != 32 {
+			return errors.New("unexpected slice size")
+		}
+	default:
+		return fmt.Errorf("unknown address family %d", family)
+	}
+
+	return r.unmarshalAddrs(addrs)
+}
+
+// appendAddr appends the raw bytes of the zoneless address a to b,
+// without the intermediate allocation of netip.Addr.AsSlice.
+func appendAddr(b []byte, a netip.Addr) []byte {
+	if a.Is4() {
+		a4 := a.As4()
+		return append(b, a4[:]...)
+	}
+	a16 := a.As16()
+	return append(b, a16[:]...)
+}
+
+// unmarshalAddrs decodes the raw boundary addresses, the slice length
+// must be 8 or 32 bytes.
+func (r *IPRange) unmarshalAddrs(data []byte) error {
+	n := len(data)
 	first, _ := netip.AddrFromSlice(data[:n/2])
 	last, _ := netip.AddrFromSlice(data[n/2:])
 
@@ -545,8 +545,8 @@ func TestMarshalUnmarshalBinary(t *testing.T) {
 			wantLength int
 		}{
 			{"ZeroValue", iprange.IPRange{}, 0},
-			{"IPv4Range", mustFromString("1.2.3.4-1.2.3.10"), 8}, // 2 * 4 bytes
-			{"IPv6Range", mustFromString("::1-::ff"), 32},        // 2 * 16 bytes
+			{"IPv4Range", mustFromString("1.2.3.4-1.2.3.10"), 10}, // 2 header + 2 * 4 bytes
+			{"IPv6Range", mustFromString("::1-::ff"), 34},         // 2 header + 2 * 16 bytes
 		}
 
 		for _, tt := range tests {
@@ -563,9 +563,75 @@ func TestMarshalUnmarshalBinary(t *testing.T) {
 		}
 	})
 
+	t.Run("AppendBinary", func(t *testing.T) {
+		t.Parallel()
+		r := mustFromString("::ffff:1.2.3.4-::ffff:1.2.3.9")
+
+		prefix := []byte("head")
+		data, err := r.AppendBinary(prefix)
+		if err != nil {
+			t.Fatalf("AppendBinary failed: %v", err)
+		}
+		if string(data[:len(prefix)]) != "head" {
+			t.Fatalf("AppendBinary clobbered the buffer: %v", data)
+		}
+
+		var decoded iprange.IPRange
+		if err := decoded.UnmarshalBinary(data[len(prefix):]); err != nil {
+			t.Fatalf("UnmarshalBinary failed: %v", err)
+		}
+		if decoded != r {
+			t.Errorf("4in6 range does not roundtrip: got %v, want %v", decoded, r)
+		}
+
+		var zero iprange.IPRange
+		if data, _ := zero.AppendBinary(prefix); string(data) != "head" {
+			t.Errorf("AppendBinary of zero value, got %v, want buffer unchanged", data)
+		}
+	})
+
+	t.Run("LegacyFormat", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			data []byte
+			want iprange.IPRange
+		}{
+			{[]byte{1, 2, 3, 4, 1, 2, 3, 9}, mustFromString("1.2.3.4-1.2.3.9")},
+			{append(mustParseAddr("::1").AsSlice(), mustParseAddr("::ff").AsSlice()...), mustFromString("::1-::ff")},
+		}
+
+		for _, tt := range tests {
+			var r iprange.IPRange
+			if err := r.UnmarshalBinary(tt.data); err != nil {
+				t.Fatalf("UnmarshalBinary(legacy %v) failed: %v", tt.data, err)
+			}
+			if r != tt.want {
+				t.Errorf("UnmarshalBinary(legacy %v), got %v, want %v", tt.data, r, tt.want)
+			}
+		}
+	})
+
 	t.Run("UnmarshalErrors", func(t *testing.T) {
 		t.Parallel()
 
+		t.Run("VersionAndFamily", func(t *testing.T) {
+			t.Parallel()
+			badBinary := [][]byte{
+				{0, 4, 1, 2, 3, 4, 1, 2, 3, 9},                // unknown version 0
+				{2, 4, 1, 2, 3, 4, 1, 2, 3, 9},                // unknown future version
+				{1, 5, 1, 2, 3, 4, 1, 2, 3, 9},                // unknown family
+				{1, 6, 1, 2, 3, 4, 1, 2, 3, 9},                // family does not match size
+				{1, 4, 1, 2, 3, 4, 1, 2, 3, 9, 0, 0, 0, 0, 0}, // trailing garbage
+			}
+
+			for _, data := range badBinary {
+				var r iprange.IPRange
+				if err := r.UnmarshalBinary(data); err == nil {
+					t.Errorf("expected error for UnmarshalBinary(%v), got nil", data)
+				}
+			}
+		})
+
 		t.Run("NilReceiver", func(t *testing.T) {
 			t.Parallel()
 			var r *iprange.IPRange
@@ -606,7 +672,7 @@ func TestMarshalUnmarshalBinary(t *testing.T) {
 			var buf [100]byte
 			for length := 1; length <= len(buf); length++ {
 				if length == 8 || length == 32 {
-					continue // these are valid lengths
+					continue // these are valid legacy lengths
 				}
 				var r iprange.IPRange
 				if err := r.UnmarshalBinary(buf[:length]); err == nil {
@@ -791,3 +857,99 @@ func TestCompare(t *testing.T) {
 		}
 	}
 }
+
+func TestAppendText(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input iprange.IPRange
+		want  string
+	}{
+		{iprange.IPRange{}, "head"},
+		{mustFromString("1.2.3.4"), "head1.2.3.4/32"},
+		{mustFromString("1.2.3.4-6.7.8.9"), "head1.2.3.4-6.7.8.9"},
+		{mustFromString("::ffff:1.2.3.4-::ffff:1.2.3.9"), "head::ffff:1.2.3.4-::ffff:1.2.3.9"},
+		{mustFromString("::1-::ff"), "head::1-::ff"},
+		{mustFromString("fe80::/10"), "headfe80::/10"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.input.AppendText([]byte("head"))
+		if err != nil {
+			t.Fatalf("AppendText failed: %v", err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("AppendText(%v), got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAppendAllocs(t *testing.T) {
+	r := mustFromString("ffff:ffff:ffff:ffff:ffff:ffff:255.255.255.0-ffff:ffff:ffff:ffff:ffff:ffff:255.255.255.254")
+	buf := make([]byte, 0, 256)
+
+	if n := testing.AllocsPerRun(100, func() { _, _ = r.AppendText(buf[:0]) }); n != 0 {
+		t.Errorf("AppendText allocs, got %v, want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { _, _ = r.AppendBinary(buf[:0]) }); n != 0 {
+		t.Errorf("AppendBinary allocs, got %v, want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { _ = r.String() }); n > 1 {
+		t.Errorf("String allocs, got %v, want <= 1", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { _, _ = r.MarshalText() }); n > 1 {
+		t.Errorf("MarshalText allocs, got %v, want <= 1", n)
+	}
+}
+
+var benchRanges = map[string]iprange.IPRange{
+	"v4prefix": mustFromString("10.0.0.0/8"),
+	"v4range":  mustFromString("10.0.0.3-10.0.17.134"),
+	"v6prefix": mustFromString("2001:db8::/32"),
+	"v6range":  mustFromString("2001:db8::1-2001:db8::f6"),
+}
+
+func BenchmarkString(b *testing.B) {
+	for name, r := range benchRanges {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = r.String()
+			}
+		})
+	}
+}
+
+func BenchmarkMarshalText(b *testing.B) {
+	for name, r := range benchRanges {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = r.MarshalText()
+			}
+		})
+	}
+}
+
+func BenchmarkAppendText(b *testing.B) {
+	buf := make([]byte, 0, 128)
+	for name, r := range benchRanges {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				buf, _ = r.AppendText(buf[:0])
+			}
+		})
+	}
+}
+
+func BenchmarkAppendBinary(b *testing.B) {
+	buf := make([]byte, 0, 64)
+	for name, r := range benchRanges {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				buf, _ = r.AppendBinary(buf[:0])
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+// Package ipscan finds IP addresses, CIDR prefixes and address ranges in free-form text,
+// e.g. threat reports and emails.
+//
+// Recognized are single addresses "10.0.0.1", prefixes "10.0.0.0/24" and
+// explicit ranges "10.0.0.1-10.0.0.9", also with whitespace around the hyphen,
+// the grammar of iprange.FromString with the relaxations common in prose.
+// Defanged notations like "10[.]0[.]0[.]1" or "2001:db8[:]:1" are refanged.
+//
+// Tokens must be delimited by non-word characters, so "std::vector" or
+// "x10.0.0.1" are no matches. The bare "::" is ignored as punctuation.
+// A colon ends a leading key, so "src:10.0.0.1" matches 10.0.0.1.
+package ipscan
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"iter"
+	"net/netip"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/internal/rangetext"
+)
+
+// Match is a token found by Scan.
+type Match struct {
+	// Range is the matched address, prefix or range.
+	Range iprange.IPRange
+
+	// Text is the token as written in the input, possibly defanged.
+	Text string
+
+	// Offset is the byte offset of Text in the input.
+	Offset int64
+
+	// Defanged reports whether Text was refanged before parsing.
+	Defanged bool
+}
+
+// Scanner reads text and finds the IP tokens in it.
+type Scanner struct {
+	r   io.Reader
+	err error
+}
+
+// NewScanner returns a Scanner reading from r.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{r: r}
+}
+
+// Scan returns an iterator over all matches in r, in order of appearance.
+// Read errors stop the iteration silently, use a Scanner to check them.
+func Scan(r io.Reader) iter.Seq[Match] {
+	return NewScanner(r).All()
+}
+
+// All returns an iterator over all matches, in order of appearance.
+// The input is consumed, the iterator is single use.
+// After the iteration, Err reports any read error.
+func (s *Scanner) All() iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		br := bufio.NewReader(s.r)
+
+		var offset int64
+		for {
+			line, err := br.ReadBytes('\n')
+			for m := range scanLine(line) {
+				m.Offset += offset
+				if !yield(m) {
+					return
+				}
+			}
+			offset += int64(len(line))
+
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					s.err = err
+				}
+				return
+			}
+		}
+	}
+}
+
+// Addrs returns an iterator over the single addresses in line, in order of appearance.
+// The tokens are delimited as in Scan, but neither refanged nor extended
+// to prefixes or ranges, "10.0.0.1-10.0.0.9" yields both addresses.
+func Addrs(line []byte) iter.Seq[netip.Addr] {
+	return func(yield func(netip.Addr) bool) {
+		for i := 0; i < len(line); {
+			if !isAddrChar(line[i]) {
+				i++
+				continue
+			}
+
+			start, end, addr, ok := addrAt(line, i)
+			if !ok {
+				i = retryAt(line, i)
+				continue
+			}
+			i = end
+
+			// the bare "::" is punctuation
+			if string(line[start:end]) == "::" {
+				continue
+			}
+			if !yield(addr) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the first non-EOF read error.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// refangs maps defanged notations to the refanged byte.
+var refangs = []struct {
+	defanged string
+	c        byte
+}{
+	{"[.]", '.'},
+	{"(.)", '.'},
+	{"{.}", '.'},
+	{"[dot]", '.'},
+	{"(dot)", '.'},
+	{"{dot}", '.'},
+	{"[:]", ':'},
+}
+
+// refang returns the refanged line and for each byte in it the offset
+// into line, with one extra element for the end of line.
+func refang(line []byte) (norm []byte, offs []int) {
+	norm = make([]byte, 0, len(line))
+	offs = make([]int, 0, len(line)+1)
+
+next:
+	for i := 0; i < len(line); {
+		if c := line[i]; c == '[' || c == '(' || c == '{' {
+			for _, rf := range refangs {
+				if n := len(rf.defanged); i+n <= len(line) && bytes.EqualFold(line[i:i+n], []byte(rf.defanged)) {
+					norm = append(norm, rf.c)
+					offs = append(offs, i)
+					i += n
+					continue next
+				}
+			}
+		}
+		norm = append(norm, line[i])
+		offs = append(offs, i)
+		i++
+	}
+
+	offs = append(offs, len(line))
+	return norm, offs
+}
+
+// scanLine yields the matches in line, offsets relative to line.
+func scanLine(line []byte) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		norm, offs := refang(line)
+
+		for i := 0; i < len(norm); {
+			if !isAddrChar(norm[i]) {
+				i++
+				continue
+			}
+
+			start, end, addr, ok := addrAt(norm, i)
+			if !ok {
+				i = retryAt(norm, i)
+				continue
+			}
+
+			r, mEnd := extend(norm, start, end, addr)
+
+			// the bare "::" is punctuation, but not as part of a prefix or range
+			if mEnd == end && string(norm[start:end]) == "::" {
+				i = end
+				continue
+			}
+			end = mEnd
+
+			m := Match{
+				Range:    r,
+				Text:     string(line[offs[start]:offs[end]]),
+				Offset:   int64(offs[start]),
+				Defanged: offs[end]-offs[start] != end-start,
+			}
+			if !yield(m) {
+				return
+			}
+			i = end
+		}
+	}
+}
+
+// addrAt parses the address in the run of address chars starting at i.
+// It returns the span of the address, trimmed by punctuation and ports.
+func addrAt(b []byte, i int) (start, end int, addr netip.Addr, ok bool) {
+	start, end = i, runEnd(b, i)
+
+	// trailing dots and a single trailing colon are punctuation
+	for end > start && b[end-1] == '.' {
+		end--
+	}
+	if end-start >= 2 && b[end-1] == ':' && b[end-2] != ':' {
+		end--
+	}
+	// a single leading colon is punctuation, e.g. "ip:10.0.0.1"
+	if end-start >= 2 && b[start] == ':' && b[start+1] != ':' {
+		start++
+	}
+
+	if !isBoundary(b, start-1) {
+		return 0, 0, addr, false
+	}
+
+	tok := string(b[start:end])
+	addr, err := netip.ParseAddr(tok)
+	if err != nil {
+		// IPv4 with port, e.g. "10.0.0.1:443"
+		j := bytes.LastIndexByte(b[start:end], ':')
+		if j < 0 {
+			return 0, 0, addr, false
+		}
+		if addr, err = netip.ParseAddr(tok[:j]); err != nil || !addr.Is4() {
+			return 0, 0, addr, false
+		}
+		return start, start + j, addr, true
+	}
+
+	if !isBoundary(b, end) {
+		return 0, 0, addr, false
+	}
+
+	return start, end, addr, true
+}
+
+// extend tries to extend the single address at b[start:end] to a prefix
+// or a range, it returns the range and the end of the match.
+func extend(b []byte, start, end int, addr netip.Addr) (iprange.IPRange, int) {
+	single, _ := iprange.FromAddrs(addr, addr)
+
+	// prefix, e.g. "10.0.0.0/24"
+	if end < len(b) && b[end] == '/' {
+		j := end + 1
+		for j < len(b) && j-end <= 3 && b[j] >= '0' && b[j] <= '9' {
+			j++
+		}
+		if j > end+1 && isBoundary(b, j) {
+			if p, err := netip.ParsePrefix(string(b[start:j])); err == nil {
+				r, _ := iprange.FromPrefix(p)
+				return r, j
+			}
+		}
+		return single, end
+	}
+
+	// range, e.g. "10.0.0.1 - 10.0.0.9"
+	j, ok := rangetext.Separator(b, end)
+	if !ok || j >= len(b) || !isAddrChar(b[j]) {
+		return single, end
+	}
+
+	start2, end2, last, ok := addrAt(b, j)
+	if !ok || start2 != j {
+		return single, end
+	}
+
+	r, err := iprange.FromAddrs(addr, last)
+	if err != nil {
+		return single, end
+	}
+	return r, end2
+}
+
+// retryAt returns the position to scan next after a failed match at i,
+// behind the next single colon in the run of address chars starting at i,
+// e.g. for "src:10.0.0.1" with the run "c:10.0.0.1". Without such a colon
+// it returns the end of the run.
+func retryAt(b []byte, i int) int {
+	end := runEnd(b, i)
+	for j := i + 1; j < end-1; j++ {
+		if b[j] == ':' && b[j-1] != ':' && b[j+1] != ':' {
+			return j + 1
+		}
+	}
+	return end
+}
+
+// runEnd returns the end of the run of address chars starting at i.
+func runEnd(b []byte, i int) int {
+	for i < len(b) && isAddrChar(b[i]) {
+		i++
+	}
+	return i
+}
+
+func isAddrChar(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' || c == '.' || c == ':'
+}
+
+// isBoundary reports whether the byte at i delimits a token, out of bounds is a boundary.
+func isBoundary(b []byte, i int) bool {
+	if i < 0 || i >= len(b) {
+		return true
+	}
+	c := b[i]
+	//nolint:staticcheck // De Morgan conversion reduces readability here
+	return !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_')
+}
@@ -0,0 +1,149 @@
+package ipscan_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/iprange/ipscan"
+)
+
+// brief formats a match as "offset:text=range[!]", "!" marks defanged tokens.
+func brief(m ipscan.Match) string {
+	s := fmt.Sprintf("%d:%s=%s", m.Offset, m.Text, m.Range)
+	if m.Defanged {
+		s += "!"
+	}
+	return s
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"nothing to see here: deadbeef, 12:34:56, 1.2.3, std::vector, a :: b", nil},
+		{"C2 at 10.0.0.1.", []string{"6:10.0.0.1=10.0.0.1/32"}},
+		{"net 10.0.0.0/24, host 10.0.0.1/33", []string{"4:10.0.0.0/24=10.0.0.0/24", "22:10.0.0.1=10.0.0.1/32"}},
+		{"masked 10.0.0.1/24", []string{"7:10.0.0.1/24=10.0.0.0/24"}},
+		{"range 10.0.0.1 - 10.0.0.9 and 10.0.0.20-10.0.0.29", []string{
+			"6:10.0.0.1 - 10.0.0.9=10.0.0.1-10.0.0.9",
+			"30:10.0.0.20-10.0.0.29=10.0.0.20-10.0.0.29",
+		}},
+		{"en dash 10.0.0.1 \u2013 10.0.0.9", []string{"8:10.0.0.1 \u2013 10.0.0.9=10.0.0.1-10.0.0.9"}},
+		{"reversed 10.0.0.9 - 10.0.0.1", []string{"9:10.0.0.9=10.0.0.9/32", "20:10.0.0.1=10.0.0.1/32"}},
+		{"mixed 10.0.0.1 - ::1", []string{"6:10.0.0.1=10.0.0.1/32", "17:::1=::1/128"}},
+		{"defanged 10[.]0[.]0[.]1 and 192(.)168{dot}1[DOT]0/24", []string{
+			"9:10[.]0[.]0[.]1=10.0.0.1/32!",
+			"28:192(.)168{dot}1[DOT]0/24=192.168.1.0/24!",
+		}},
+		{"v6 2001:db8[:]:1 and [2001:db8::2]:443", []string{
+			"3:2001:db8[:]:1=2001:db8::1/128!",
+			"22:2001:db8::2=2001:db8::2/128",
+		}},
+		{"ports 10.0.0.1:443 ip:10.0.0.2", []string{"6:10.0.0.1=10.0.0.1/32", "22:10.0.0.2=10.0.0.2/32"}},
+		{"::ffff:1.2.3.4 ::/0 :: ::-::ff", []string{
+			"0:::ffff:1.2.3.4=::ffff:1.2.3.4/128",
+			"15:::/0=::/0",
+			"23:::-::ff=::/120",
+		}},
+		{"fe80::1%eth0 x10.0.0.1 10.0.0.1x", []string{"0:fe80::1=fe80::1/128"}},
+		{"src:10.0.0.1 id:10.0.0.2/24 fwd:10.0.0.3-10.0.0.9", []string{
+			"4:10.0.0.1=10.0.0.1/32",
+			"16:10.0.0.2/24=10.0.0.0/24",
+			"32:10.0.0.3-10.0.0.9=10.0.0.3-10.0.0.9",
+		}},
+		{"src:2001:db8::1 dead:beef:10.0.0.1 2001:db8::1::2", []string{
+			"4:2001:db8::1=2001:db8::1/128",
+			"26:10.0.0.1=10.0.0.1/32",
+		}},
+		{"line 1 10.0.0.1\nline 2 10.0.0.2\r\n", []string{"7:10.0.0.1=10.0.0.1/32", "23:10.0.0.2=10.0.0.2/32"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for m := range ipscan.Scan(strings.NewReader(tt.input)) {
+			got = append(got, brief(m))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Scan(%q)\ngot:  %q\nwant: %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestScanOffsets(t *testing.T) {
+	t.Parallel()
+	input := "first 10[.]0[.]0[.]1 then 10.0.0.2 - 10.0.0.3\nnext 2001:db8::/32\n"
+
+	for m := range ipscan.Scan(strings.NewReader(input)) {
+		if got := input[m.Offset : m.Offset+int64(len(m.Text))]; got != m.Text {
+			t.Errorf("Offset %d of %q points to %q", m.Offset, m.Text, got)
+		}
+	}
+}
+
+func TestAddrs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"std::vector, a :: b, x10.0.0.1 10.0.0.1x abc10.0.0.7", nil},
+		{"range 10.0.0.1 - 10.0.0.9, net 10.0.1.0/24", []string{"10.0.0.1", "10.0.0.9", "10.0.1.0"}},
+		{"ports 10.0.0.1:443 [2001:db8::1]:8443 ip:10.0.0.2.", []string{"10.0.0.1", "2001:db8::1", "10.0.0.2"}},
+		{"defanged 10[.]0[.]0[.]1", nil},
+		{"src:10.0.0.1 id:10.0.0.2 fwd:10.0.0.3 src:2001:db8::1", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "2001:db8::1"}},
+		{"12:34:56 std::vector 2001:db8::1::2", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for a := range ipscan.Addrs([]byte(tt.line)) {
+			got = append(got, a.String())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Addrs(%q), got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestScanBreak(t *testing.T) {
+	t.Parallel()
+	n := 0
+	for range ipscan.Scan(strings.NewReader("10.0.0.1 10.0.0.2\n10.0.0.3")) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("break in iteration, got %d matches, want 2", n)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("boom") }
+
+func TestScannerErr(t *testing.T) {
+	t.Parallel()
+	s := ipscan.NewScanner(io.MultiReader(strings.NewReader("10.0.0.1 "), errReader{}))
+
+	var got []string
+	for m := range s.All() {
+		got = append(got, m.Text)
+	}
+
+	if s.Err() == nil {
+		t.Errorf("Err(), expected read error, got nil")
+	}
+	if !slices.Equal(got, []string{"10.0.0.1"}) {
+		t.Errorf("matches before read error, got %q", got)
+	}
+}
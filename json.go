@@ -0,0 +1,153 @@
+package iprange
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+// JSONObject wraps an IPRange for peers that exchange ranges as JSON objects
+// instead of plain JSON strings:
+//
+//	{"start":"10.0.0.3","end":"10.0.0.9","cidrs":["10.0.0.3/32","10.0.0.4/30","10.0.0.8/31"]}
+//
+// On output the object form is written, the zero value is written as null.
+// On input the object form and the plain string form of MarshalText
+// are both accepted, so the same struct can talk to both kinds of peers.
+// The object fields "first" and "last" are accepted as aliases for "start" and "end".
+type JSONObject struct {
+	IPRange
+}
+
+// jsonObject is the wire format of JSONObject.
+type jsonObject struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	CIDRs []string `json:"cidrs"`
+}
+
+// jsonObjectIn is the accepted input format of JSONObject, with aliases.
+type jsonObjectIn struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	First string   `json:"first"`
+	Last  string   `json:"last"`
+	CIDRs []string `json:"cidrs"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o JSONObject) MarshalJSON() ([]byte, error) {
+	r := o.IPRange
+	if !r.IsValid() {
+		return []byte("null"), nil
+	}
+
+	obj := jsonObject{
+		Start: r.first.String(),
+		End:   r.last.String(),
+	}
+	for pfx := range r.Prefixes() {
+		obj.CIDRs = append(obj.CIDRs, pfx.String())
+	}
+
+	return json.Marshal(obj)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It accepts a JSON string as parsed by FromString, the object form and null.
+//
+// In the object form either the boundaries or the cidrs must be given.
+// If both are given, the merged cidrs must describe the same range.
+// It returns an error if the receiver is nil or is not the zero value.
+func (o *JSONObject) UnmarshalJSON(data []byte) error {
+	if o == nil {
+		return errors.New("UnmarshalJSON on nil receiver")
+	}
+
+	if o.IPRange != zeroValue {
+		return errors.New("refusing to Unmarshal into non-zero IPRange")
+	}
+
+	data = bytes.TrimSpace(data)
+	switch {
+	case string(data) == "null":
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return o.UnmarshalText([]byte(s))
+	}
+
+	var obj jsonObjectIn
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	start := cmp.Or(obj.Start, obj.First)
+	end := cmp.Or(obj.End, obj.Last)
+
+	var fromCIDRs IPRange
+	if len(obj.CIDRs) > 0 {
+		var err error
+		if fromCIDRs, err = rangeFromCIDRs(obj.CIDRs); err != nil {
+			return err
+		}
+	}
+
+	if start == "" && end == "" {
+		if !fromCIDRs.IsValid() {
+			return errors.New("missing start, end and cidrs")
+		}
+		o.IPRange = fromCIDRs
+		return nil
+	}
+
+	first, err := netip.ParseAddr(start)
+	if err != nil {
+		return err
+	}
+
+	last, err := netip.ParseAddr(end)
+	if err != nil {
+		return err
+	}
+
+	r, err := FromAddrs(first, last)
+	if err != nil {
+		return err
+	}
+
+	if fromCIDRs.IsValid() && fromCIDRs != r {
+		return fmt.Errorf("cidrs %s do not match range %s", fromCIDRs, r)
+	}
+
+	o.IPRange = r
+	return nil
+}
+
+// rangeFromCIDRs merges the list of prefixes, they must form a single contiguous range.
+func rangeFromCIDRs(cidrs []string) (IPRange, error) {
+	rs := make([]IPRange, 0, len(cidrs))
+	for _, s := range cidrs {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return zeroValue, err
+		}
+		r, err := FromPrefix(p)
+		if err != nil {
+			return zeroValue, err
+		}
+		rs = append(rs, r)
+	}
+
+	merged := Merge(rs)
+	if len(merged) != 1 {
+		return zeroValue, fmt.Errorf("cidrs are not contiguous: %v", merged)
+	}
+	return merged[0], nil
+}
@@ -0,0 +1,100 @@
+package iprange_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestJSONObjectMarshal(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input iprange.IPRange
+		want  string
+	}{
+		{iprange.IPRange{}, `null`},
+		{mustFromString("10.0.0.0/24"), `{"start":"10.0.0.0","end":"10.0.0.255","cidrs":["10.0.0.0/24"]}`},
+		{mustFromString("10.0.0.3-10.0.0.9"), `{"start":"10.0.0.3","end":"10.0.0.9","cidrs":["10.0.0.3/32","10.0.0.4/30","10.0.0.8/31"]}`},
+		{mustFromString("2001:db8::1-2001:db8::3"), `{"start":"2001:db8::1","end":"2001:db8::3","cidrs":["2001:db8::1/128","2001:db8::2/127"]}`},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(iprange.JSONObject{tt.input})
+		if err != nil {
+			t.Fatalf("Marshal(%v) failed: %v", tt.input, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%v)\ngot:  %s\nwant: %s", tt.input, got, tt.want)
+		}
+
+		var decoded iprange.JSONObject
+		if err := json.Unmarshal(got, &decoded); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", got, err)
+		}
+		if decoded.IPRange != tt.input {
+			t.Errorf("roundtrip, got %v, want %v", decoded.IPRange, tt.input)
+		}
+	}
+}
+
+func TestJSONObjectUnmarshal(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  iprange.IPRange
+	}{
+		{`null`, iprange.IPRange{}},
+		{`"10.0.0.1-10.0.0.10"`, mustFromString("10.0.0.1-10.0.0.10")},
+		{`"2001:db8::/32"`, mustFromString("2001:db8::/32")},
+		{`{"start":"10.0.0.1","end":"10.0.0.10"}`, mustFromString("10.0.0.1-10.0.0.10")},
+		{`{"first":"10.0.0.1","last":"10.0.0.10"}`, mustFromString("10.0.0.1-10.0.0.10")},
+		{`{"cidrs":["10.0.0.8/31","10.0.0.4/30"]}`, mustFromString("10.0.0.4-10.0.0.9")},
+		{` {"start":"::1","end":"::3","cidrs":["::1/128","::2/127"]} `, mustFromString("::1-::3")},
+	}
+
+	for _, tt := range tests {
+		var got iprange.JSONObject
+		if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.input, err)
+		}
+		if got.IPRange != tt.want {
+			t.Errorf("Unmarshal(%s), got %v, want %v", tt.input, got.IPRange, tt.want)
+		}
+	}
+}
+
+func TestJSONObjectUnmarshalErrors(t *testing.T) {
+	t.Parallel()
+	tests := []string{
+		`{}`,
+		`[]`,
+		`42`,
+		`"10.0.0.1-"`,
+		`{"start":"10.0.0.1"}`,
+		`{"start":"10.0.0.9","end":"10.0.0.1"}`,
+		`{"start":"10.0.0.1","end":"::1"}`,
+		`{"cidrs":["10.0.0.0/31","10.0.0.4/30"]}`,
+		`{"cidrs":["10.0.0.0/33"]}`,
+		`{"start":"10.0.0.0","end":"10.0.0.3","cidrs":["10.0.0.0/31"]}`,
+	}
+
+	for _, input := range tests {
+		var got iprange.JSONObject
+		if err := json.Unmarshal([]byte(input), &got); err == nil {
+			t.Errorf("Unmarshal(%s), expected error, got %v", input, got.IPRange)
+		}
+	}
+
+	// non-zero receiver
+	got := iprange.JSONObject{mustFromString("1.2.3.4")}
+	if err := got.UnmarshalJSON([]byte(`"1.2.3.4"`)); err == nil {
+		t.Errorf("expected error when UnmarshalJSON into non-zero receiver, got nil")
+	}
+
+	// nil receiver
+	var nilObj *iprange.JSONObject
+	if err := nilObj.UnmarshalJSON([]byte(`"1.2.3.4"`)); err == nil {
+		t.Errorf("expected error when UnmarshalJSON on nil receiver, got nil")
+	}
+}
@@ -0,0 +1,315 @@
+// Package lease assigns single addresses from a pool of ranges for a limited
+// time, as DHCP servers and VPN gateways do.
+//
+// Clients are identified by a key, e.g. a MAC address or a user name.
+// Assignments are sticky: a client gets its previous address again,
+// as long as it has not been assigned to another client in the meantime.
+package lease
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gaissmai/iprange"
+)
+
+var (
+	// ErrExhausted is returned if no free address is left.
+	ErrExhausted = errors.New("no free address")
+
+	// ErrNotLeased is returned by Renew and Release for an address
+	// without an active lease of the client.
+	ErrNotLeased = errors.New("address not leased by client")
+)
+
+// Lease is the assignment of an address to a client.
+// The lease is active until Expiry.
+type Lease struct {
+	Addr   netip.Addr
+	Client string
+	Expiry time.Time
+}
+
+// Options configure a Pool.
+type Options struct {
+	// Exclude are the ranges never to assign, e.g. network, gateway
+	// and broadcast address.
+	Exclude []iprange.IPRange
+
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+// State is the serializable state of a Pool, e.g. with encoding/json,
+// ranges and addresses marshal as text. The leases are sorted by address,
+// expired leases are kept for the sticky assignment.
+type State struct {
+	Ranges  []iprange.IPRange
+	Exclude []iprange.IPRange
+	Leases  []Lease
+}
+
+// Pool assigns addresses of its ranges.
+// It is safe for concurrent use.
+type Pool struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	ranges   []iprange.IPRange // given ranges
+	exclude  []iprange.IPRange // given exclusions
+	usable   []iprange.IPRange // ranges without exclusions
+	unleased []iprange.IPRange // usable addresses never leased
+	expiries expiryHeap        // leases by expiry, with outdated entries
+	leases   map[netip.Addr]Lease
+	byClient map[string]netip.Addr
+}
+
+// New returns a Pool for the ranges.
+func New(ranges []iprange.IPRange, opts Options) *Pool {
+	p := &Pool{
+		now:      opts.Now,
+		ranges:   iprange.Merge(ranges),
+		exclude:  iprange.Merge(opts.Exclude),
+		leases:   make(map[netip.Addr]Lease),
+		byClient: make(map[string]netip.Addr),
+	}
+	if p.now == nil {
+		p.now = time.Now
+	}
+
+	for _, r := range p.ranges {
+		p.usable = append(p.usable, r.Remove(p.exclude)...)
+	}
+	p.unleased = slices.Clone(p.usable)
+	return p
+}
+
+// Restore returns a Pool with the state of a Snapshot.
+// The Exclude option is taken from the state.
+// It returns an error if a lease is outside of the pool or
+// an address or a client has more than one lease.
+func Restore(s State, opts Options) (*Pool, error) {
+	opts.Exclude = s.Exclude
+	p := New(s.Ranges, opts)
+
+	leased := make([]iprange.IPRange, 0, len(s.Leases))
+	for _, l := range s.Leases {
+		if !p.inPool(l.Addr) {
+			return nil, fmt.Errorf("lease %s: address not in pool", l.Addr)
+		}
+		if _, ok := p.leases[l.Addr]; ok {
+			return nil, fmt.Errorf("lease %s: duplicate address", l.Addr)
+		}
+		if _, ok := p.byClient[l.Client]; ok && l.Client != "" {
+			return nil, fmt.Errorf("lease %s: duplicate client %q", l.Addr, l.Client)
+		}
+		p.bind(l)
+
+		r, _ := iprange.FromAddrs(l.Addr, l.Addr)
+		leased = append(leased, r)
+	}
+
+	p.unleased = nil
+	for _, r := range p.usable {
+		p.unleased = append(p.unleased, r.Remove(leased)...)
+	}
+	return p, nil
+}
+
+// Snapshot returns the state of the pool.
+func (p *Pool) Snapshot() State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return State{
+		Ranges:  slices.Clone(p.ranges),
+		Exclude: slices.Clone(p.exclude),
+		Leases:  p.sortedLeases(func(Lease) bool { return true }),
+	}
+}
+
+// Lease assigns an address to the client for the duration ttl.
+//
+// A client with an active lease gets it renewed. Otherwise the client
+// gets its previous address, if still free, or the lowest address never
+// leased, or the address of the earliest expired lease of another client,
+// the lowest address for equal expiry.
+// An empty client key gets an address without stickiness.
+func (p *Pool) Lease(client string, ttl time.Duration) (Lease, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+
+	// active lease or previous address, expired but not yet reassigned
+	if a, ok := p.byClient[client]; ok && client != "" {
+		l := p.leases[a]
+		if l.Client == client {
+			l.Expiry = now.Add(ttl)
+			p.bind(l)
+			return l, nil
+		}
+	}
+
+	a, ok := p.takeUnleased()
+	if !ok {
+		a, ok = p.takeExpired(now)
+	}
+	if !ok {
+		return Lease{}, ErrExhausted
+	}
+
+	// steal the expired lease of another client
+	if old, ok := p.leases[a]; ok {
+		delete(p.byClient, old.Client)
+	}
+
+	l := Lease{Addr: a, Client: client, Expiry: now.Add(ttl)}
+	p.bind(l)
+	return l, nil
+}
+
+// Renew extends the active lease of the client for the address by ttl from now.
+func (p *Pool) Renew(a netip.Addr, client string, ttl time.Duration) (Lease, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	l, ok := p.leases[a]
+	if !ok || l.Client != client || !l.Expiry.After(now) {
+		return Lease{}, ErrNotLeased
+	}
+
+	l.Expiry = now.Add(ttl)
+	p.bind(l)
+	return l, nil
+}
+
+// Release ends the active lease of the client for the address.
+// The address stays bound to the client for the sticky assignment
+// until it is leased to another client.
+func (p *Pool) Release(a netip.Addr, client string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	l, ok := p.leases[a]
+	if !ok || l.Client != client || !l.Expiry.After(now) {
+		return ErrNotLeased
+	}
+
+	l.Expiry = now
+	p.bind(l)
+	return nil
+}
+
+// Leases returns the active leases, sorted by address.
+func (p *Pool) Leases() []Lease {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	return p.sortedLeases(func(l Lease) bool { return l.Expiry.After(now) })
+}
+
+// bind records the lease and the binding of the client to the address.
+func (p *Pool) bind(l Lease) {
+	p.leases[l.Addr] = l
+	if l.Client != "" {
+		p.byClient[l.Client] = l.Addr
+	}
+
+	// rebuild now and then, dropping the outdated entries
+	if len(p.expiries) < 2*len(p.leases)+64 {
+		heap.Push(&p.expiries, l)
+		return
+	}
+
+	p.expiries = p.expiries[:0]
+	for _, l := range p.leases {
+		p.expiries = append(p.expiries, l)
+	}
+	heap.Init(&p.expiries)
+}
+
+// takeUnleased removes the lowest never leased address from the pool.
+func (p *Pool) takeUnleased() (netip.Addr, bool) {
+	if len(p.unleased) == 0 {
+		return netip.Addr{}, false
+	}
+
+	a, _ := p.unleased[0].AddrAt(0)
+	r, _ := iprange.FromAddrs(a, a)
+	p.unleased = slices.Replace(p.unleased, 0, 1, p.unleased[0].Remove([]iprange.IPRange{r})...)
+	return a, true
+}
+
+// takeExpired returns the address of the earliest expired lease.
+func (p *Pool) takeExpired(now time.Time) (netip.Addr, bool) {
+	for len(p.expiries) > 0 {
+		e := p.expiries[0]
+
+		// outdated by a later bind of the address
+		if l := p.leases[e.Addr]; !l.Expiry.Equal(e.Expiry) {
+			heap.Pop(&p.expiries)
+			continue
+		}
+
+		if e.Expiry.After(now) {
+			break
+		}
+		heap.Pop(&p.expiries)
+		return e.Addr, true
+	}
+	return netip.Addr{}, false
+}
+
+func (p *Pool) inPool(a netip.Addr) bool {
+	for _, r := range p.usable {
+		first, last := r.Addrs()
+		if first.Compare(a) <= 0 && a.Compare(last) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pool) sortedLeases(keep func(Lease) bool) []Lease {
+	var out []Lease
+	for _, l := range p.leases {
+		if keep(l) {
+			out = append(out, l)
+		}
+	}
+	slices.SortFunc(out, func(x, y Lease) int { return x.Addr.Compare(y.Addr) })
+	return out
+}
+
+// expiryHeap is a min-heap of leases by expiry, then by address.
+type expiryHeap []Lease
+
+func (h expiryHeap) Len() int { return len(h) }
+
+func (h expiryHeap) Less(i, j int) bool {
+	if c := h[i].Expiry.Compare(h[j].Expiry); c != 0 {
+		return c < 0
+	}
+	return h[i].Addr.Less(h[j].Addr)
+}
+
+func (h expiryHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *expiryHeap) Push(x any) { *h = append(*h, x.(Lease)) }
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,282 @@
+package lease_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/lease"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newPool(clock *fakeClock) *lease.Pool {
+	return lease.New(
+		[]iprange.IPRange{mustFromString("192.168.1.0/29")},
+		lease.Options{
+			// network, gateway and broadcast
+			Exclude: []iprange.IPRange{
+				mustFromString("192.168.1.0-192.168.1.1"),
+				mustFromString("192.168.1.7"),
+			},
+			Now: clock.Now,
+		},
+	)
+}
+
+func TestLease(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := newPool(clock)
+
+	var got []netip.Addr
+	for _, c := range []string{"a", "b", "c", "d", "e"} {
+		l, err := p.Lease(c, time.Hour)
+		if err != nil {
+			t.Fatalf("Lease(%s) failed: %v", c, err)
+		}
+		if l.Client != c || !l.Expiry.Equal(clock.Now().Add(time.Hour)) {
+			t.Errorf("Lease(%s), got %+v", c, l)
+		}
+		got = append(got, l.Addr)
+	}
+
+	want := []netip.Addr{
+		netip.MustParseAddr("192.168.1.2"),
+		netip.MustParseAddr("192.168.1.3"),
+		netip.MustParseAddr("192.168.1.4"),
+		netip.MustParseAddr("192.168.1.5"),
+		netip.MustParseAddr("192.168.1.6"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Lease, got %v, want %v", got, want)
+	}
+
+	if _, err := p.Lease("f", time.Hour); !errors.Is(err, lease.ErrExhausted) {
+		t.Errorf("Lease(f), got %v, want ErrExhausted", err)
+	}
+
+	// active lease is renewed
+	clock.Advance(30 * time.Minute)
+	l, err := p.Lease("b", time.Hour)
+	if err != nil || l.Addr != want[1] || !l.Expiry.Equal(clock.Now().Add(time.Hour)) {
+		t.Errorf("Lease(b) again, got %+v %v", l, err)
+	}
+
+	// all but b expired, f steals the earliest expired, lowest address
+	clock.Advance(45 * time.Minute)
+	if got := p.Leases(); len(got) != 1 || got[0].Client != "b" {
+		t.Errorf("Leases, got %v, want only b", got)
+	}
+	l, err = p.Lease("f", time.Hour)
+	if err != nil || l.Addr != want[0] {
+		t.Errorf("Lease(f), got %+v %v, want %s", l, err, want[0])
+	}
+
+	// a lost its address, c gets its previous one
+	if l, _ := p.Lease("c", time.Hour); l.Addr != want[2] {
+		t.Errorf("Lease(c), got %s, want sticky %s", l.Addr, want[2])
+	}
+	if l, _ := p.Lease("a", time.Hour); l.Addr != want[3] {
+		t.Errorf("Lease(a), got %s, want %s", l.Addr, want[3])
+	}
+}
+
+func TestRenewRelease(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := newPool(clock)
+
+	l, _ := p.Lease("a", time.Hour)
+
+	if _, err := p.Renew(l.Addr, "b", time.Hour); !errors.Is(err, lease.ErrNotLeased) {
+		t.Errorf("Renew by other client, got %v, want ErrNotLeased", err)
+	}
+
+	clock.Advance(50 * time.Minute)
+	r, err := p.Renew(l.Addr, "a", time.Hour)
+	if err != nil || !r.Expiry.Equal(clock.Now().Add(time.Hour)) {
+		t.Errorf("Renew, got %+v %v", r, err)
+	}
+
+	if err := p.Release(l.Addr, "b"); !errors.Is(err, lease.ErrNotLeased) {
+		t.Errorf("Release by other client, got %v, want ErrNotLeased", err)
+	}
+	if err := p.Release(l.Addr, "a"); err != nil {
+		t.Errorf("Release, got %v", err)
+	}
+	if err := p.Release(l.Addr, "a"); !errors.Is(err, lease.ErrNotLeased) {
+		t.Errorf("Release twice, got %v, want ErrNotLeased", err)
+	}
+	if _, err := p.Renew(l.Addr, "a", time.Hour); !errors.Is(err, lease.ErrNotLeased) {
+		t.Errorf("Renew after Release, got %v, want ErrNotLeased", err)
+	}
+
+	// never leased addresses first, a stays sticky
+	if l2, _ := p.Lease("b", time.Hour); l2.Addr == l.Addr {
+		t.Errorf("Lease(b), got released address of a %s", l2.Addr)
+	}
+	if l2, _ := p.Lease("a", time.Hour); l2.Addr != l.Addr {
+		t.Errorf("Lease(a), got %s, want sticky %s", l2.Addr, l.Addr)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := newPool(clock)
+
+	_, _ = p.Lease("a", time.Hour)
+	_, _ = p.Lease("b", 2*time.Hour)
+	_, _ = p.Lease("", time.Hour)
+
+	data, err := json.Marshal(p.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"Ranges":["192.168.1.0/29"],"Exclude":["192.168.1.0/31","192.168.1.7/32"],"Leases":[` +
+		`{"Addr":"192.168.1.2","Client":"a","Expiry":"2024-01-01T01:00:00Z"},` +
+		`{"Addr":"192.168.1.3","Client":"b","Expiry":"2024-01-01T02:00:00Z"},` +
+		`{"Addr":"192.168.1.4","Client":"","Expiry":"2024-01-01T01:00:00Z"}]}`
+	if string(data) != want {
+		t.Errorf("Snapshot, got %s, want %s", data, want)
+	}
+
+	var s lease.State
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	q, err := lease.Restore(s, lease.Options{Now: clock.Now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(q.Leases(), p.Leases()) {
+		t.Errorf("Restore, got %v, want %v", q.Leases(), p.Leases())
+	}
+
+	// no double assignment after restart
+	if l, _ := q.Lease("c", time.Hour); l.Addr != netip.MustParseAddr("192.168.1.5") {
+		t.Errorf("Lease(c) after Restore, got %s, want 192.168.1.5", l.Addr)
+	}
+
+	bad := [][]lease.Lease{
+		{{Addr: netip.MustParseAddr("192.168.1.7"), Client: "x"}},
+		{{Addr: netip.MustParseAddr("10.0.0.1"), Client: "x"}},
+		{{Addr: netip.MustParseAddr("192.168.1.2"), Client: "x"}, {Addr: netip.MustParseAddr("192.168.1.2"), Client: "y"}},
+		{{Addr: netip.MustParseAddr("192.168.1.2"), Client: "x"}, {Addr: netip.MustParseAddr("192.168.1.3"), Client: "x"}},
+	}
+	for _, ls := range bad {
+		s := lease.State{Ranges: s.Ranges, Exclude: s.Exclude, Leases: ls}
+		if _, err := lease.Restore(s, lease.Options{}); err == nil {
+			t.Errorf("Restore(%v), expected error", ls)
+		}
+	}
+}
+
+func TestConcurrentLease(t *testing.T) {
+	t.Parallel()
+	p := lease.New([]iprange.IPRange{mustFromString("10.0.0.0/24")}, lease.Options{})
+
+	var mu sync.Mutex
+	seen := make(map[netip.Addr]bool)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 32 {
+				l, err := p.Lease(string(rune('a'+i))+string(rune('a'+j)), time.Hour)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[l.Addr] {
+					t.Errorf("Lease, %s assigned twice", l.Addr)
+				}
+				seen[l.Addr] = true
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	if len(p.Leases()) != 256 {
+		t.Errorf("Leases, got %d, want 256", len(p.Leases()))
+	}
+}
+
+func TestLeaseMany(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := lease.New([]iprange.IPRange{mustFromString("10.0.0.0/16")}, lease.Options{Now: clock.Now})
+
+	const n = 20000
+	seen := make(map[netip.Addr]bool, n)
+	for i := range n {
+		l, err := p.Lease("a"+strconv.Itoa(i), time.Duration(i+1)*time.Second)
+		if err != nil {
+			t.Fatalf("Lease(a%d) failed: %v", i, err)
+		}
+		if seen[l.Addr] {
+			t.Fatalf("Lease(a%d), %s assigned twice", i, l.Addr)
+		}
+		seen[l.Addr] = true
+	}
+
+	// renew all, exhaust the rest, then steal the earliest expired leases
+	for i := range n {
+		if _, err := p.Lease("a"+strconv.Itoa(i), time.Duration(i+1)*time.Second); err != nil {
+			t.Fatalf("Lease(a%d) again failed: %v", i, err)
+		}
+	}
+	for i := n; i < 65536; i++ {
+		if _, err := p.Lease("b"+strconv.Itoa(i), 24*time.Hour); err != nil {
+			t.Fatalf("Lease(b%d) failed: %v", i, err)
+		}
+	}
+	if _, err := p.Lease("c", time.Hour); !errors.Is(err, lease.ErrExhausted) {
+		t.Fatalf("Lease(c), got %v, want ErrExhausted", err)
+	}
+
+	clock.Advance(time.Hour)
+	for i := range 3600 {
+		l, err := p.Lease("c"+strconv.Itoa(i), time.Hour)
+		if err != nil {
+			t.Fatalf("Lease(c%d) failed: %v", i, err)
+		}
+		if want := netip.AddrFrom4([4]byte{10, 0, byte(i >> 8), byte(i)}); l.Addr != want {
+			t.Fatalf("Lease(c%d), got %s, want earliest expired %s", i, l.Addr, want)
+		}
+	}
+}
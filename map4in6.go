@@ -0,0 +1,91 @@
+package iprange
+
+import "net/netip"
+
+// mapped4in6 is the IPv4-mapped IPv6 block ::ffff:0:0/96.
+var mapped4in6 = IPRange{
+	netip.AddrFrom16([16]byte{10: 0xff, 11: 0xff}),
+	netip.AddrFrom16([16]byte{10: 0xff, 11: 0xff, 12: 0xff, 13: 0xff, 14: 0xff, 15: 0xff}),
+}
+
+// Unmap returns r with IPv4-mapped IPv6 addresses converted to IPv4,
+// e.g. ::ffff:1.2.3.0/120 becomes 1.2.3.0/24.
+// Ranges not entirely within ::ffff:0:0/96 are returned unchanged, see UnmapAll.
+func (r IPRange) Unmap() IPRange {
+	if r.first.Is4In6() && r.last.Is4In6() {
+		return IPRange{r.first.Unmap(), r.last.Unmap()}
+	}
+	return r
+}
+
+// Map4In6 returns the IPv4 range r as IPv4-mapped IPv6 range,
+// e.g. 1.2.3.0/24 becomes ::ffff:1.2.3.0/120.
+// IPv6 and invalid ranges are returned unchanged.
+func (r IPRange) Map4In6() IPRange {
+	if !r.first.Is4() {
+		return r
+	}
+	return IPRange{
+		netip.AddrFrom16(r.first.As16()),
+		netip.AddrFrom16(r.last.As16()),
+	}
+}
+
+// UnmapAll returns the ranges with the IPv4-mapped IPv6 addresses converted
+// to IPv4, so that they unify with the IPv4 ranges.
+// IPv6 ranges partially overlapping ::ffff:0:0/96 are split into the IPv6 parts
+// and the unmapped IPv4 part. The result is not sorted.
+//
+// Dual-stack listeners report IPv4 clients in both forms,
+// use UnmapAll before comparing the addresses with IPv4 ranges.
+func UnmapAll(in []IPRange) []IPRange {
+	out := make([]IPRange, 0, len(in))
+	for _, r := range in {
+		if !r.first.Is6() || r.isDisjunct(mapped4in6) {
+			out = append(out, r)
+			continue
+		}
+
+		if r.first.Less(mapped4in6.first) {
+			out = append(out, IPRange{r.first, mapped4in6.first.Prev()})
+		}
+
+		inner := r
+		if inner.first.Less(mapped4in6.first) {
+			inner.first = mapped4in6.first
+		}
+		if mapped4in6.last.Less(inner.last) {
+			inner.last = mapped4in6.last
+		}
+		out = append(out, inner.Unmap())
+
+		if mapped4in6.last.Less(r.last) {
+			out = append(out, IPRange{mapped4in6.last.Next(), r.last})
+		}
+	}
+	return out
+}
+
+// MergeUnmapped is like Merge, but IPv4-mapped IPv6 ranges are unmapped
+// before, see UnmapAll, and merge with the IPv4 ranges.
+func MergeUnmapped(in []IPRange) []IPRange {
+	return Merge(UnmapAll(in))
+}
+
+// RemoveUnmapped is like Remove, but r and the exclusions are unmapped
+// before, see UnmapAll, so that IPv4-mapped IPv6 exclusions remove the
+// IPv4 addresses and vice versa. The remaining IPv4-mapped addresses
+// are returned as IPv4.
+func (r IPRange) RemoveUnmapped(in []IPRange) []IPRange {
+	if r == zeroValue {
+		return nil
+	}
+
+	exclude := UnmapAll(in)
+
+	var out []IPRange
+	for _, part := range UnmapAll([]IPRange{r}) {
+		out = append(out, part.Remove(exclude)...)
+	}
+	return Merge(out)
+}
@@ -0,0 +1,118 @@
+package iprange_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestUnmapMap4In6(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in    iprange.IPRange
+		unmap iprange.IPRange
+		map46 iprange.IPRange
+	}{
+		{mustFromString("::ffff:1.2.3.0/120"), mustFromString("1.2.3.0/24"), mustFromString("::ffff:1.2.3.0/120")},
+		{mustFromString("::ffff:0:0/96"), mustFromString("0.0.0.0/0"), mustFromString("::ffff:0:0/96")},
+		{mustFromString("1.2.3.4-1.2.3.5"), mustFromString("1.2.3.4-1.2.3.5"), mustFromString("::ffff:1.2.3.4-::ffff:1.2.3.5")},
+		{mustFromString("0.0.0.0/0"), mustFromString("0.0.0.0/0"), mustFromString("::ffff:0:0/96")},
+		// partially mapped, unchanged
+		{mustFromString("::fffe:ffff:ffff-::ffff:0:1"), mustFromString("::fffe:ffff:ffff-::ffff:0:1"), mustFromString("::fffe:ffff:ffff-::ffff:0:1")},
+		{mustFromString("2001:db8::/32"), mustFromString("2001:db8::/32"), mustFromString("2001:db8::/32")},
+		{iprange.IPRange{}, iprange.IPRange{}, iprange.IPRange{}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.in.Unmap(); got != tt.unmap {
+			t.Errorf("Unmap(%v), got %v, want %v", tt.in, got, tt.unmap)
+		}
+		if got := tt.in.Map4In6(); got != tt.map46 {
+			t.Errorf("Map4In6(%v), got %v, want %v", tt.in, got, tt.map46)
+		}
+	}
+}
+
+func TestUnmapAll(t *testing.T) {
+	t.Parallel()
+	in := []iprange.IPRange{
+		mustFromString("10.0.0.0/8"),
+		mustFromString("::ffff:192.168.0.0/112"),
+		mustFromString("::fffe:ffff:ffff-::1:0:0:1"),
+		mustFromString("2001:db8::/32"),
+	}
+
+	want := []iprange.IPRange{
+		mustFromString("10.0.0.0/8"),
+		mustFromString("192.168.0.0/16"),
+		mustFromString("::fffe:ffff:ffff-::fffe:ffff:ffff"),
+		mustFromString("0.0.0.0/0"),
+		mustFromString("::1:0:0:0-::1:0:0:1"),
+		mustFromString("2001:db8::/32"),
+	}
+
+	if got := iprange.UnmapAll(in); !slices.Equal(got, want) {
+		t.Errorf("UnmapAll(%v), got %v, want %v", in, got, want)
+	}
+}
+
+func TestMergeUnmapped(t *testing.T) {
+	t.Parallel()
+	in := []iprange.IPRange{
+		mustFromString("1.2.3.4"),
+		mustFromString("::ffff:1.2.3.5"),
+		mustFromString("::ffff:1.2.3.6/127"),
+		mustFromString("2001:db8::1"),
+	}
+
+	want := []iprange.IPRange{
+		mustFromString("1.2.3.4-1.2.3.7"),
+		mustFromString("2001:db8::1"),
+	}
+	if got := iprange.MergeUnmapped(in); !slices.Equal(got, want) {
+		t.Errorf("MergeUnmapped(%v), got %v, want %v", in, got, want)
+	}
+
+	// Merge keeps the families apart
+	if got := iprange.Merge(in); len(got) != 3 {
+		t.Errorf("Merge(%v), got %v, want 3 ranges", in, got)
+	}
+}
+
+func TestRemoveUnmapped(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		r    iprange.IPRange
+		in   []iprange.IPRange
+		want []iprange.IPRange
+	}{
+		{
+			mustFromString("10.0.0.0/24"),
+			[]iprange.IPRange{mustFromString("::ffff:10.0.0.0/121")},
+			[]iprange.IPRange{mustFromString("10.0.0.128/25")},
+		},
+		{
+			mustFromString("::ffff:10.0.0.0/120"),
+			[]iprange.IPRange{mustFromString("10.0.0.0/25")},
+			[]iprange.IPRange{mustFromString("10.0.0.128/25")},
+		},
+		{
+			mustFromString("::/64"),
+			[]iprange.IPRange{mustFromString("0.0.0.0/1"), mustFromString("::1")},
+			[]iprange.IPRange{
+				mustFromString("128.0.0.0/1"),
+				mustFromString("::"),
+				mustFromString("::2-::fffe:ffff:ffff"),
+				mustFromString("::1:0:0:0-::ffff:ffff:ffff:ffff"),
+			},
+		},
+		{iprange.IPRange{}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		if got := tt.r.RemoveUnmapped(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("RemoveUnmapped(%v, %v), got %v, want %v", tt.r, tt.in, got, tt.want)
+		}
+	}
+}
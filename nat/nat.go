@@ -0,0 +1,231 @@
+// Package nat translates addresses and ranges by one-to-one mappings of
+// equally sized inside and outside ranges, as used by static NAT and NPTv6.
+package nat
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/internal/u128"
+)
+
+// Mapping maps the Inside range one-to-one onto the equally sized Outside range,
+// the n-th inside address onto the n-th outside address.
+type Mapping struct {
+	Inside  iprange.IPRange
+	Outside iprange.IPRange
+}
+
+// NewMapping returns the mapping of inside onto outside.
+// The ranges must be valid, of the same address family and of equal size.
+func NewMapping(inside, outside iprange.IPRange) (Mapping, error) {
+	if !inside.IsValid() || !outside.IsValid() {
+		return Mapping{}, errors.New("invalid IPRange")
+	}
+
+	inFirst, inLast := inside.Addrs()
+	outFirst, outLast := outside.Addrs()
+	if inFirst.Is4() != outFirst.Is4() {
+		return Mapping{}, fmt.Errorf("different IP versions: %s and %s", inside, outside)
+	}
+
+	inSize, _ := u128.From(inLast).Sub(u128.From(inFirst))
+	outSize, _ := u128.From(outLast).Sub(u128.From(outFirst))
+	if inSize != outSize {
+		return Mapping{}, fmt.Errorf("different sizes: %s and %s", inside, outside)
+	}
+
+	return Mapping{Inside: inside, Outside: outside}, nil
+}
+
+// Inverse returns the mapping from outside to inside.
+func (m Mapping) Inverse() Mapping {
+	return Mapping{Inside: m.Outside, Outside: m.Inside}
+}
+
+// Translate returns the outside address of the inside address a,
+// ok is false if a is not within the inside range.
+func (m Mapping) Translate(a netip.Addr) (netip.Addr, bool) {
+	if !contains(m.Inside, a) {
+		return netip.Addr{}, false
+	}
+	return m.translate(a), true
+}
+
+// TranslateRange returns the outside range of the inside range r,
+// ok is false if r is not within the inside range.
+func (m Mapping) TranslateRange(r iprange.IPRange) (iprange.IPRange, bool) {
+	first, last := r.Addrs()
+	if !r.IsValid() || !contains(m.Inside, first) || !contains(m.Inside, last) {
+		return iprange.IPRange{}, false
+	}
+
+	out, err := iprange.FromAddrs(m.translate(first), m.translate(last))
+	return out, err == nil
+}
+
+// translate maps a, which must be within the inside range.
+func (m Mapping) translate(a netip.Addr) netip.Addr {
+	inFirst, _ := m.Inside.Addrs()
+	outFirst, _ := m.Outside.Addrs()
+
+	offset, _ := u128.From(a).Sub(u128.From(inFirst))
+	b, _ := u128.From(outFirst).Add(offset)
+	return b.Addr(outFirst.Is4())
+}
+
+// String returns the mapping as "inside -> outside".
+func (m Mapping) String() string {
+	return m.Inside.String() + " -> " + m.Outside.String()
+}
+
+// MappingTable is a set of mappings with disjoint inside ranges and
+// disjoint outside ranges, so that the translation is unambiguous in
+// both directions.
+//
+// A MappingTable is immutable and safe for concurrent use.
+type MappingTable struct {
+	mappings []Mapping // sorted by inside range
+}
+
+// NewMappingTable returns the table of the mappings.
+// It returns an error if a mapping is not valid as by NewMapping,
+// or if inside ranges or outside ranges overlap.
+func NewMappingTable(mappings ...Mapping) (*MappingTable, error) {
+	for _, m := range mappings {
+		if _, err := NewMapping(m.Inside, m.Outside); err != nil {
+			return nil, err
+		}
+	}
+
+	ms := slices.Clone(mappings)
+
+	if err := checkDisjoint(ms, func(m Mapping) iprange.IPRange { return m.Outside }); err != nil {
+		return nil, fmt.Errorf("outside: %w", err)
+	}
+	if err := checkDisjoint(ms, func(m Mapping) iprange.IPRange { return m.Inside }); err != nil {
+		return nil, fmt.Errorf("inside: %w", err)
+	}
+
+	return &MappingTable{mappings: ms}, nil
+}
+
+// sortBy sorts the mappings by the ranges of one side.
+func sortBy(ms []Mapping, side func(Mapping) iprange.IPRange) {
+	slices.SortFunc(ms, func(a, b Mapping) int {
+		ll, rr, _, _ := iprange.Compare(side(a), side(b))
+		if ll != 0 {
+			return ll
+		}
+		return -rr
+	})
+}
+
+// checkDisjoint sorts the mappings by the side and reports the first overlap.
+func checkDisjoint(ms []Mapping, side func(Mapping) iprange.IPRange) error {
+	sortBy(ms, side)
+
+	for i := 1; i < len(ms); i++ {
+		_, _, _, rl := iprange.Compare(side(ms[i-1]), side(ms[i]))
+		if rl >= 0 {
+			return fmt.Errorf("%s overlaps %s", side(ms[i-1]), side(ms[i]))
+		}
+	}
+	return nil
+}
+
+// Mappings returns the mappings of the table, sorted by inside range.
+func (t *MappingTable) Mappings() []Mapping {
+	return slices.Clone(t.mappings)
+}
+
+// Inverse returns the table of the inverse mappings.
+func (t *MappingTable) Inverse() *MappingTable {
+	ms := make([]Mapping, 0, len(t.mappings))
+	for _, m := range t.mappings {
+		ms = append(ms, m.Inverse())
+	}
+	sortBy(ms, func(m Mapping) iprange.IPRange { return m.Inside })
+	return &MappingTable{mappings: ms}
+}
+
+// Translate returns the outside address of the inside address a,
+// ok is false if a is not within any inside range.
+func (t *MappingTable) Translate(a netip.Addr) (netip.Addr, bool) {
+	if i, ok := t.lookup(a); ok {
+		return t.mappings[i].translate(a), true
+	}
+	return netip.Addr{}, false
+}
+
+// TranslateRange returns the outside ranges of the inside range r, in the
+// order of the inside addresses. A range spanning several mappings is
+// translated piecewise. ok is false if any address of r is not within an
+// inside range.
+func (t *MappingTable) TranslateRange(r iprange.IPRange) (out []iprange.IPRange, ok bool) {
+	if !r.IsValid() {
+		return nil, false
+	}
+
+	first, last := r.Addrs()
+	i, ok := t.lookup(first)
+	if !ok {
+		return nil, false
+	}
+
+	for ; i < len(t.mappings); i++ {
+		m := t.mappings[i]
+		mFirst, mLast := m.Inside.Addrs()
+
+		// gap between the mappings
+		if first.Less(mFirst) {
+			return nil, false
+		}
+
+		pieceLast := last
+		if mLast.Less(last) {
+			pieceLast = mLast
+		}
+
+		piece, _ := m.TranslateRange(mustFromAddrs(first, pieceLast))
+		out = append(out, piece)
+
+		if pieceLast == last {
+			return out, true
+		}
+		first = pieceLast.Next()
+	}
+
+	return nil, false
+}
+
+// lookup returns the index of the mapping with a in the inside range.
+func (t *MappingTable) lookup(a netip.Addr) (int, bool) {
+	i, found := slices.BinarySearchFunc(t.mappings, a, func(m Mapping, a netip.Addr) int {
+		first, last := m.Inside.Addrs()
+		switch {
+		case last.Less(a):
+			return -1
+		case a.Less(first):
+			return 1
+		}
+		return 0
+	})
+	return i, found
+}
+
+func contains(r iprange.IPRange, a netip.Addr) bool {
+	first, last := r.Addrs()
+	return r.IsValid() && first.Compare(a) <= 0 && a.Compare(last) <= 0
+}
+
+func mustFromAddrs(first, last netip.Addr) iprange.IPRange {
+	r, err := iprange.FromAddrs(first, last)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
@@ -0,0 +1,198 @@
+package nat_test
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/nat"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func mustMapping(inside, outside string) nat.Mapping {
+	m, err := nat.NewMapping(mustFromString(inside), mustFromString(outside))
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func TestNewMapping(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		inside, outside string
+		ok              bool
+	}{
+		{"10.0.0.0/24", "192.0.2.0/24", true},
+		{"10.0.0.5-10.0.1.4", "192.0.2.0/24", true},
+		{"fd00::/48", "2001:db8:1::/48", true},
+		{"10.0.0.0/24", "192.0.2.0/25", false},
+		{"10.0.0.0/24", "2001:db8::/120", false},
+		{"::/0", "::/0", true},
+	}
+
+	for _, tt := range tests {
+		_, err := nat.NewMapping(mustFromString(tt.inside), mustFromString(tt.outside))
+		if (err == nil) != tt.ok {
+			t.Errorf("NewMapping(%s, %s), got error %v, want ok %v", tt.inside, tt.outside, err, tt.ok)
+		}
+	}
+
+	if _, err := nat.NewMapping(iprange.IPRange{}, mustFromString("10.0.0.0/8")); err == nil {
+		t.Errorf("NewMapping(zero value), expected error")
+	}
+}
+
+func TestMappingTranslate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		m    nat.Mapping
+		in   string
+		want string
+		ok   bool
+	}{
+		{mustMapping("10.0.0.0/24", "192.0.2.0/24"), "10.0.0.17", "192.0.2.17", true},
+		{mustMapping("10.0.0.5-10.0.1.4", "192.0.2.0/24"), "10.0.1.0", "192.0.2.251", true},
+		{mustMapping("10.0.0.0/24", "192.0.2.0/24"), "10.0.1.0", "", false},
+		{mustMapping("10.0.0.0/24", "192.0.2.0/24"), "::1", "", false},
+		// carry across the 64-bit halves
+		{mustMapping("fd00::ffff:ffff:ffff:ffff-fd00:0:0:1::1", "2001:db8::-2001:db8::2"), "fd00:0:0:1::1", "2001:db8::2", true},
+		{mustMapping("2001:db8::-2001:db8::2", "fd00::ffff:ffff:ffff:ffff-fd00:0:0:1::1"), "2001:db8::1", "fd00:0:0:1::", true},
+	}
+
+	for _, tt := range tests {
+		got, ok := tt.m.Translate(netip.MustParseAddr(tt.in))
+		if ok != tt.ok {
+			t.Errorf("%v: Translate(%s), got ok %v, want %v", tt.m, tt.in, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if want := netip.MustParseAddr(tt.want); got != want {
+			t.Errorf("%v: Translate(%s), got %s, want %s", tt.m, tt.in, got, want)
+		}
+
+		// and back
+		back, ok := tt.m.Inverse().Translate(got)
+		if !ok || back != netip.MustParseAddr(tt.in) {
+			t.Errorf("%v: Inverse().Translate(%s), got %s %v, want %s", tt.m, got, back, ok, tt.in)
+		}
+	}
+}
+
+func TestMappingTranslateRange(t *testing.T) {
+	t.Parallel()
+	m := mustMapping("10.0.0.0/24", "192.0.2.0/24")
+
+	got, ok := m.TranslateRange(mustFromString("10.0.0.128/25"))
+	if !ok || got != mustFromString("192.0.2.128/25") {
+		t.Errorf("TranslateRange, got %v %v, want 192.0.2.128/25", got, ok)
+	}
+
+	if _, ok := m.TranslateRange(mustFromString("10.0.0.128-10.0.1.1")); ok {
+		t.Errorf("TranslateRange beyond inside, expected not ok")
+	}
+}
+
+func TestMappingTable(t *testing.T) {
+	t.Parallel()
+	tbl, err := nat.NewMappingTable(
+		mustMapping("10.0.1.0/24", "198.51.100.0/24"),
+		mustMapping("10.0.0.0/24", "192.0.2.0/24"),
+		mustMapping("fd00::/64", "2001:db8::/64"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ms := tbl.Mappings()
+	if len(ms) != 3 || ms[0].Inside != mustFromString("10.0.0.0/24") {
+		t.Errorf("Mappings, got %v, want sorted by inside", ms)
+	}
+
+	for _, tt := range []struct{ in, want string }{
+		{"10.0.0.1", "192.0.2.1"},
+		{"10.0.1.1", "198.51.100.1"},
+		{"fd00::42", "2001:db8::42"},
+	} {
+		got, ok := tbl.Translate(netip.MustParseAddr(tt.in))
+		if !ok || got != netip.MustParseAddr(tt.want) {
+			t.Errorf("Translate(%s), got %s %v, want %s", tt.in, got, ok, tt.want)
+		}
+		back, ok := tbl.Inverse().Translate(got)
+		if !ok || back != netip.MustParseAddr(tt.in) {
+			t.Errorf("Inverse().Translate(%s), got %s %v, want %s", got, back, ok, tt.in)
+		}
+	}
+
+	if _, ok := tbl.Translate(netip.MustParseAddr("10.0.2.1")); ok {
+		t.Errorf("Translate(10.0.2.1), expected not ok")
+	}
+
+	// across two mappings
+	got, ok := tbl.TranslateRange(mustFromString("10.0.0.250-10.0.1.5"))
+	want := []iprange.IPRange{mustFromString("192.0.2.250-192.0.2.255"), mustFromString("198.51.100.0-198.51.100.5")}
+	if !ok || !slices.Equal(got, want) {
+		t.Errorf("TranslateRange, got %v %v, want %v", got, ok, want)
+	}
+
+	// partly unmapped
+	for _, s := range []string{"10.0.1.250-10.0.2.5", "9.255.255.255-10.0.0.5"} {
+		if got, ok := tbl.TranslateRange(mustFromString(s)); ok {
+			t.Errorf("TranslateRange(%s), got %v, expected not ok", s, got)
+		}
+	}
+}
+
+func TestMappingTableGap(t *testing.T) {
+	t.Parallel()
+	tbl, err := nat.NewMappingTable(
+		mustMapping("10.0.0.0/24", "192.0.2.0/24"),
+		mustMapping("10.0.2.0/24", "198.51.100.0/24"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, ok := tbl.TranslateRange(mustFromString("10.0.0.0-10.0.2.255")); ok {
+		t.Errorf("TranslateRange over gap, got %v, expected not ok", got)
+	}
+}
+
+func TestMappingTableOverlap(t *testing.T) {
+	t.Parallel()
+	tests := [][]nat.Mapping{
+		{mustMapping("10.0.0.0/24", "192.0.2.0/24"), mustMapping("10.0.0.128/25", "198.51.100.0/25")},
+		{mustMapping("10.0.0.0/24", "192.0.2.0/24"), mustMapping("10.0.1.0/25", "192.0.2.128/25")},
+		{mustMapping("10.0.0.0/24", "192.0.2.0/24"), mustMapping("10.0.0.0/24", "198.51.100.0/24")},
+	}
+
+	for _, ms := range tests {
+		if _, err := nat.NewMappingTable(ms...); err == nil {
+			t.Errorf("NewMappingTable(%v), expected error", ms)
+		}
+	}
+
+	if _, err := nat.NewMappingTable(nat.Mapping{}); err == nil {
+		t.Errorf("NewMappingTable(zero Mapping), expected error")
+	}
+
+	// literal mappings bypassing NewMapping
+	for _, m := range []nat.Mapping{
+		{Inside: mustFromString("10.0.1.0/24"), Outside: mustFromString("192.168.0.0/30")},
+		{Inside: mustFromString("10.0.1.0/24"), Outside: mustFromString("2001:db8::/120")},
+	} {
+		if _, err := nat.NewMappingTable(m); err == nil {
+			t.Errorf("NewMappingTable(%v), expected error", m)
+		}
+	}
+}
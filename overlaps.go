@@ -0,0 +1,144 @@
+package iprange
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// Overlap is a conflict between two input ranges, see FindOverlaps.
+type Overlap struct {
+	// I and J are the indices of the input ranges, I < J.
+	I, J int
+
+	// Range is the overlapping part, the zero value for adjacent ranges.
+	Range IPRange
+
+	// Adjacent reports ranges not overlapping but touching,
+	// which Merge joins, e.g. 10.0.0.0/25 and 10.0.0.128/25.
+	Adjacent bool
+}
+
+// FindOverlaps returns all pairs of overlapping or adjacent ranges of the
+// input, with the indices into rs. Invalid ranges are ignored.
+//
+// It is a sweep line over the ranges sorted by first address, running in
+// O(n log n + k) for n ranges and k reported pairs. The pairs are reported
+// in sweep order, by the first address of the later range.
+func FindOverlaps(rs []IPRange) []Overlap {
+	order := make([]int, 0, len(rs))
+	for i, r := range rs {
+		if r != zeroValue {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return cmpRange(rs[i], rs[j]) })
+
+	var out []Overlap
+
+	// active ranges, min-heap by last address
+	active := &activeHeap{ranges: rs}
+	for _, j := range order {
+		r := rs[j]
+
+		// drop the ranges ending before r, but keep the adjacent ones
+		for active.Len() > 0 {
+			a := rs[active.idx[0]]
+			if !a.last.Less(r.first) || a.last.Next() == r.first {
+				break
+			}
+			heap.Pop(active)
+		}
+
+		// all active ranges overlap or touch r
+		for _, i := range active.idx {
+			a := rs[i]
+			o := Overlap{I: min(i, j), J: max(i, j)}
+
+			if a.last.Less(r.first) {
+				o.Adjacent = true
+			} else {
+				o.Range = IPRange{r.first, a.last}
+				if r.last.Less(a.last) {
+					o.Range.last = r.last
+				}
+			}
+			out = append(out, o)
+		}
+
+		heap.Push(active, j)
+	}
+
+	return out
+}
+
+// activeHeap is a min-heap of range indices by last address.
+type activeHeap struct {
+	ranges []IPRange
+	idx    []int
+}
+
+func (h *activeHeap) Len() int { return len(h.idx) }
+
+func (h *activeHeap) Less(i, j int) bool {
+	return h.ranges[h.idx[i]].last.Less(h.ranges[h.idx[j]].last)
+}
+
+func (h *activeHeap) Swap(i, j int) { h.idx[i], h.idx[j] = h.idx[j], h.idx[i] }
+
+func (h *activeHeap) Push(x any) { h.idx = append(h.idx, x.(int)) }
+
+func (h *activeHeap) Pop() any {
+	n := len(h.idx)
+	x := h.idx[n-1]
+	h.idx = h.idx[:n-1]
+	return x
+}
+
+// MergedRange is an output range of MergeWithSources.
+type MergedRange struct {
+	Range IPRange
+
+	// Sources are the indices of the input ranges merged into Range,
+	// including subsets and adjacent ranges, in ascending order.
+	Sources []int
+}
+
+// MergeWithSources is like Merge, but each output range carries the
+// indices of the contributing input ranges.
+func MergeWithSources(in []IPRange) []MergedRange {
+	order := make([]int, 0, len(in))
+	for i, r := range in {
+		if r != zeroValue {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return cmpRange(in[i], in[j]) })
+
+	var out []MergedRange
+	for _, i := range order {
+		r := in[i]
+
+		if len(out) == 0 {
+			out = append(out, MergedRange{Range: r, Sources: []int{i}})
+			continue
+		}
+
+		topic := &out[len(out)-1]
+
+		// disjoint and not adjacent, see Merge
+		if topic.Range.isDisjunctLeft(r) && topic.Range.last.Next() != r.first {
+			out = append(out, MergedRange{Range: r, Sources: []int{i}})
+			continue
+		}
+
+		if topic.Range.last.Less(r.last) {
+			topic.Range.last = r.last
+		}
+		topic.Sources = append(topic.Sources, i)
+	}
+
+	for _, m := range out {
+		slices.Sort(m.Sources)
+	}
+	return out
+}
@@ -0,0 +1,159 @@
+package iprange_test
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestFindOverlaps(t *testing.T) {
+	t.Parallel()
+	rs := []iprange.IPRange{
+		mustFromString("10.0.0.0/24"),          // 0
+		mustFromString("10.0.1.0/24"),          // 1, adjacent to 0
+		mustFromString("10.0.0.128-10.0.1.10"), // 2, overlaps 0 and 1
+		mustFromString("192.168.0.0/16"),       // 3
+		{},                                     // 4, ignored
+		mustFromString("192.168.5.5"),          // 5, within 3
+		mustFromString("2001:db8::/32"),        // 6
+		mustFromString("2001:db8::/32"),        // 7, duplicate of 6
+		mustFromString("172.16.0.0/12"),        // 8, no conflict
+	}
+
+	got := findOverlapsSorted(rs)
+	want := []iprange.Overlap{
+		{I: 0, J: 1, Adjacent: true},
+		{I: 0, J: 2, Range: mustFromString("10.0.0.128/25")},
+		{I: 1, J: 2, Range: mustFromString("10.0.1.0-10.0.1.10")},
+		{I: 3, J: 5, Range: mustFromString("192.168.5.5")},
+		{I: 6, J: 7, Range: mustFromString("2001:db8::/32")},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("FindOverlaps, got %v, want %v", got, want)
+	}
+
+	if got := iprange.FindOverlaps(nil); got != nil {
+		t.Errorf("FindOverlaps(nil), got %v, want nil", got)
+	}
+}
+
+func TestFindOverlapsEdges(t *testing.T) {
+	t.Parallel()
+	rs := []iprange.IPRange{
+		mustFromString("255.255.255.0/24"),
+		mustFromString("::/1"),
+		mustFromString("255.255.255.255"),
+		mustFromString("ffff::/16"),
+		mustFromString("8000::-ffff::"),
+	}
+
+	got := findOverlapsSorted(rs)
+	want := []iprange.Overlap{
+		{I: 0, J: 2, Range: mustFromString("255.255.255.255")},
+		{I: 1, J: 4, Adjacent: true},
+		{I: 3, J: 4, Range: mustFromString("ffff::")},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("FindOverlaps, got %v, want %v", got, want)
+	}
+}
+
+// TestFindOverlapsBruteForce compares the sweep with all pairs.
+func TestFindOverlapsBruteForce(t *testing.T) {
+	t.Parallel()
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	for range 20 {
+		var rs []iprange.IPRange
+		for range 50 {
+			a, b := prng.IntN(256), prng.IntN(256)
+			r, _ := iprange.FromAddrs(
+				netip.AddrFrom4([4]byte{10, 0, 0, byte(min(a, b))}),
+				netip.AddrFrom4([4]byte{10, 0, 0, byte(max(a, b))}),
+			)
+			rs = append(rs, r)
+		}
+
+		var want []iprange.Overlap
+		for i := range rs {
+			for j := i + 1; j < len(rs); j++ {
+				ai, bi := rs[i].Addrs()
+				aj, bj := rs[j].Addrs()
+				switch {
+				case bi.Next() == aj || bj.Next() == ai:
+					want = append(want, iprange.Overlap{I: i, J: j, Adjacent: true})
+				case bi.Less(aj) || bj.Less(ai):
+				default:
+					first, last := ai, bi
+					if first.Less(aj) {
+						first = aj
+					}
+					if bj.Less(last) {
+						last = bj
+					}
+					r, _ := iprange.FromAddrs(first, last)
+					want = append(want, iprange.Overlap{I: i, J: j, Range: r})
+				}
+			}
+		}
+
+		if got := findOverlapsSorted(rs); !slices.Equal(got, want) {
+			t.Fatalf("FindOverlaps(%v), got %v, want %v", rs, got, want)
+		}
+	}
+}
+
+// findOverlapsSorted returns the overlaps sorted by indices.
+func findOverlapsSorted(rs []iprange.IPRange) []iprange.Overlap {
+	got := iprange.FindOverlaps(rs)
+	slices.SortFunc(got, func(a, b iprange.Overlap) int {
+		return cmp.Or(cmp.Compare(a.I, b.I), cmp.Compare(a.J, b.J))
+	})
+	return got
+}
+
+func TestMergeWithSources(t *testing.T) {
+	t.Parallel()
+	in := []iprange.IPRange{
+		mustFromString("10.0.1.0/24"),          // 0, adjacent to 2
+		mustFromString("192.168.0.0/16"),       // 1
+		mustFromString("10.0.0.0/24"),          // 2
+		mustFromString("10.0.0.5"),             // 3, subset of 2
+		{},                                     // 4, ignored
+		mustFromString("192.168.1.0/24"),       // 5, subset of 1
+		mustFromString("2001:db8::/32"),        // 6
+		mustFromString("10.0.1.200-10.0.2.10"), // 7, overlaps 0
+	}
+
+	got := iprange.MergeWithSources(in)
+
+	want := []iprange.MergedRange{
+		{Range: mustFromString("10.0.0.0-10.0.2.10"), Sources: []int{0, 2, 3, 7}},
+		{Range: mustFromString("192.168.0.0/16"), Sources: []int{1, 5}},
+		{Range: mustFromString("2001:db8::/32"), Sources: []int{6}},
+	}
+
+	if !slices.EqualFunc(got, want, func(a, b iprange.MergedRange) bool {
+		return a.Range == b.Range && slices.Equal(a.Sources, b.Sources)
+	}) {
+		t.Errorf("MergeWithSources, got %v, want %v", got, want)
+	}
+
+	// same ranges as Merge
+	var ranges []iprange.IPRange
+	for _, m := range got {
+		ranges = append(ranges, m.Range)
+	}
+	if merged := iprange.Merge(in); !slices.Equal(ranges, merged) {
+		t.Errorf("MergeWithSources, got ranges %v, Merge %v", ranges, merged)
+	}
+
+	if got := iprange.MergeWithSources(nil); got != nil {
+		t.Errorf("MergeWithSources(nil), got %v, want nil", got)
+	}
+}
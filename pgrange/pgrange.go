@@ -0,0 +1,184 @@
+// Package pgrange converts between iprange.IPRange and the textual forms
+// of PostgreSQL types used to store address ranges.
+//
+// Supported are the ip4r, ip6r and iprange types of the ip4r extension,
+// and the builtin int8range and numrange types holding addresses as integers.
+//
+// All conversions are exact, arbitrary ranges are never rounded to prefixes.
+package pgrange
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/internal/rangetext"
+)
+
+// FormatIP4R returns the text form of r as printed by the ip4r extension
+// for the ip4r, ip6r and iprange types.
+//
+// Single addresses are printed without prefix length, CIDR-aligned ranges
+// in prefix notation and all other ranges as "first-last".
+// If r is invalid, it returns the empty string.
+func FormatIP4R(r iprange.IPRange) string {
+	if !r.IsValid() {
+		return ""
+	}
+
+	first, last := r.Addrs()
+	if first == last {
+		return first.String()
+	}
+
+	return r.String()
+}
+
+// ParseIP4R parses the text input accepted by the ip4r extension:
+// a single address, a CIDR prefix or an explicit range "first-last",
+// optionally with whitespace around the hyphen.
+//
+// Unlike iprange.FromString, a prefix with host bits set is rejected,
+// as ip4r does.
+func ParseIP4R(s string) (iprange.IPRange, error) {
+	s = strings.TrimSpace(s)
+
+	// a single prefix, ranges are parsed by rangetext
+	if strings.Contains(s, "/") && !strings.Contains(s, "-") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return iprange.IPRange{}, err
+		}
+		if p != p.Masked() {
+			return iprange.IPRange{}, fmt.Errorf("invalid ip4r value %q, host bits set", s)
+		}
+		return iprange.FromPrefix(p)
+	}
+
+	return rangetext.Parse(s)
+}
+
+// FormatInt8Range returns r as PostgreSQL int8range literal, the addresses
+// as unsigned integers in the canonical half-open form "[first,last+1)".
+// It returns an error if r is not a valid IPv4 range.
+func FormatInt8Range(r iprange.IPRange) (string, error) {
+	first, _ := r.Addrs()
+	if !first.Is4() {
+		return "", errors.New("int8range supports only valid IPv4 ranges")
+	}
+	return FormatNumRange(r), nil
+}
+
+// ParseInt8Range parses a PostgreSQL int8range literal into an IPv4 range.
+// See ParseNumRange for the accepted forms.
+func ParseInt8Range(s string) (iprange.IPRange, error) {
+	return parseRange(s, 32)
+}
+
+// FormatNumRange returns r as PostgreSQL numrange literal, the addresses
+// as unsigned integers in the half-open form "[first,last+1)".
+// The address family is not part of the literal.
+// If r is invalid, it returns the empty string.
+func FormatNumRange(r iprange.IPRange) string {
+	if !r.IsValid() {
+		return ""
+	}
+
+	first, last := r.Addrs()
+	lo := addrToInt(first)
+	hi := addrToInt(last)
+	hi.Add(hi, big.NewInt(1))
+
+	return fmt.Sprintf("[%s,%s)", lo, hi)
+}
+
+// ParseNumRange parses a PostgreSQL numrange (or int8range) literal into an
+// IPv4 range, or into an IPv6 range if is6 is true.
+//
+// All bound types "[a,b]", "[a,b)", "(a,b]" and "(a,b)" are accepted.
+// An omitted bound, e.g. "[a,)", extends to the beginning or end of the address space.
+// It returns an error for the empty range, for fractional bounds and
+// for bounds outside the address space.
+func ParseNumRange(s string, is6 bool) (iprange.IPRange, error) {
+	if is6 {
+		return parseRange(s, 128)
+	}
+	return parseRange(s, 32)
+}
+
+// parseRange parses the range literal s, the bounds are addresses with the given bit length.
+func parseRange(s string, bits int) (iprange.IPRange, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "empty") {
+		return iprange.IPRange{}, errors.New("empty range")
+	}
+
+	if len(s) < 3 {
+		return iprange.IPRange{}, fmt.Errorf("invalid range literal %q", s)
+	}
+
+	open, body, closing := s[0], s[1:len(s)-1], s[len(s)-1]
+	if (open != '[' && open != '(') || (closing != ']' && closing != ')') {
+		return iprange.IPRange{}, fmt.Errorf("invalid range literal %q", s)
+	}
+
+	lower, upper, found := strings.Cut(body, ",")
+	if !found {
+		return iprange.IPRange{}, fmt.Errorf("invalid range literal %q", s)
+	}
+
+	maxVal := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	maxVal.Sub(maxVal, big.NewInt(1))
+
+	lo := new(big.Int)
+	if lower = unquote(lower); lower != "" {
+		if _, ok := lo.SetString(lower, 10); !ok {
+			return iprange.IPRange{}, fmt.Errorf("invalid lower bound %q", lower)
+		}
+		if open == '(' {
+			lo.Add(lo, big.NewInt(1))
+		}
+	}
+
+	hi := new(big.Int).Set(maxVal)
+	if upper = unquote(upper); upper != "" {
+		if _, ok := hi.SetString(upper, 10); !ok {
+			return iprange.IPRange{}, fmt.Errorf("invalid upper bound %q", upper)
+		}
+		if closing == ')' {
+			hi.Sub(hi, big.NewInt(1))
+		}
+	}
+
+	if lo.Sign() < 0 || hi.Cmp(maxVal) > 0 {
+		return iprange.IPRange{}, fmt.Errorf("range %q out of address space", s)
+	}
+	if hi.Cmp(lo) < 0 {
+		return iprange.IPRange{}, fmt.Errorf("empty range %q", s)
+	}
+
+	return iprange.FromAddrs(intToAddr(lo, bits), intToAddr(hi, bits))
+}
+
+// unquote trims whitespace and the optional double quotes around a bound.
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// addrToInt returns the address as unsigned integer.
+func addrToInt(a netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(a.AsSlice())
+}
+
+// intToAddr returns the address with the given bit length, i must fit.
+func intToAddr(i *big.Int, bits int) netip.Addr {
+	a, _ := netip.AddrFromSlice(i.FillBytes(make([]byte, bits/8)))
+	return a
+}
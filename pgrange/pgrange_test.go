@@ -0,0 +1,173 @@
+package pgrange_test
+
+import (
+	"testing"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/pgrange"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestIP4R(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   iprange.IPRange
+		want string
+	}{
+		{iprange.IPRange{}, ""},
+		{mustFromString("10.0.0.1"), "10.0.0.1"},
+		{mustFromString("10.0.0.0/8"), "10.0.0.0/8"},
+		{mustFromString("10.0.0.3-10.0.17.134"), "10.0.0.3-10.0.17.134"},
+		{mustFromString("2001:db8::1"), "2001:db8::1"},
+		{mustFromString("2001:db8::/32"), "2001:db8::/32"},
+		{mustFromString("2001:db8::1-2001:db8::f6"), "2001:db8::1-2001:db8::f6"},
+	}
+
+	for _, tt := range tests {
+		got := pgrange.FormatIP4R(tt.in)
+		if got != tt.want {
+			t.Errorf("FormatIP4R(%v), got %q, want %q", tt.in, got, tt.want)
+		}
+		if !tt.in.IsValid() {
+			continue
+		}
+
+		back, err := pgrange.ParseIP4R(got)
+		if err != nil {
+			t.Fatalf("ParseIP4R(%q) failed: %v", got, err)
+		}
+		if back != tt.in {
+			t.Errorf("ParseIP4R(%q), got %v, want %v", got, back, tt.in)
+		}
+	}
+}
+
+func TestParseIP4R(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want iprange.IPRange
+	}{
+		{" 10.0.0.1 - 10.0.0.9 ", mustFromString("10.0.0.1-10.0.0.9")},
+		{"10.0.0.0-10.0.0.255", mustFromString("10.0.0.0/24")},
+		{"2001:db8:: - 2001:db8::ff", mustFromString("2001:db8::/120")},
+	}
+
+	for _, tt := range tests {
+		got, err := pgrange.ParseIP4R(tt.in)
+		if err != nil {
+			t.Fatalf("ParseIP4R(%q) failed: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseIP4R(%q), got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "10.0.0.1/24", "10.0.0.9-10.0.0.1", "10.0.0.1-::1", "2001:db8::1/64", "10.0.0.0/33"} {
+		if got, err := pgrange.ParseIP4R(in); err == nil {
+			t.Errorf("ParseIP4R(%q), expected error, got %v", in, got)
+		}
+	}
+}
+
+func TestInt8Range(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   iprange.IPRange
+		want string
+	}{
+		{mustFromString("0.0.0.0"), "[0,1)"},
+		{mustFromString("10.0.0.0/8"), "[167772160,184549376)"},
+		{mustFromString("10.0.0.3-10.0.17.134"), "[167772163,167776647)"},
+		{mustFromString("0.0.0.0/0"), "[0,4294967296)"},
+	}
+
+	for _, tt := range tests {
+		got, err := pgrange.FormatInt8Range(tt.in)
+		if err != nil {
+			t.Fatalf("FormatInt8Range(%v) failed: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("FormatInt8Range(%v), got %q, want %q", tt.in, got, tt.want)
+		}
+
+		back, err := pgrange.ParseInt8Range(got)
+		if err != nil {
+			t.Fatalf("ParseInt8Range(%q) failed: %v", got, err)
+		}
+		if back != tt.in {
+			t.Errorf("ParseInt8Range(%q), got %v, want %v", got, back, tt.in)
+		}
+	}
+
+	for _, in := range []iprange.IPRange{{}, mustFromString("::/0"), mustFromString("::ffff:1.2.3.4")} {
+		if got, err := pgrange.FormatInt8Range(in); err == nil {
+			t.Errorf("FormatInt8Range(%v), expected error, got %q", in, got)
+		}
+	}
+}
+
+func TestParseNumRange(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		is6  bool
+		want iprange.IPRange
+	}{
+		{"[167772163,167776647)", false, mustFromString("10.0.0.3-10.0.17.134")},
+		{"[167772163,167776646]", false, mustFromString("10.0.0.3-10.0.17.134")},
+		{"(167772162,167776647)", false, mustFromString("10.0.0.3-10.0.17.134")},
+		{"(167772162,167776646]", false, mustFromString("10.0.0.3-10.0.17.134")},
+		{` ["167772163", "167776647") `, false, mustFromString("10.0.0.3-10.0.17.134")},
+		{"[,)", false, mustFromString("0.0.0.0/0")},
+		{"(,)", true, mustFromString("::/0")},
+		{"[4026531840,)", false, mustFromString("240.0.0.0/4")},
+		{"[1,256)", true, mustFromString("::1-::ff")},
+		{"[42540766411282592856903984951653826560,42540766411282592856903984951653826816)", true, mustFromString("2001:db8::/120")},
+		{"[0,340282366920938463463374607431768211456)", true, mustFromString("::/0")},
+	}
+
+	for _, tt := range tests {
+		got, err := pgrange.ParseNumRange(tt.in, tt.is6)
+		if err != nil {
+			t.Fatalf("ParseNumRange(%q, %v) failed: %v", tt.in, tt.is6, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseNumRange(%q, %v), got %v, want %v", tt.in, tt.is6, got, tt.want)
+		}
+	}
+
+	// roundtrip for IPv6
+	r := mustFromString("2001:db8::1-2001:db8::f6")
+	back, err := pgrange.ParseNumRange(pgrange.FormatNumRange(r), true)
+	if err != nil || back != r {
+		t.Errorf("NumRange roundtrip of %v, got %v, %v", r, back, err)
+	}
+
+	invalid := []string{
+		"",
+		"empty",
+		"[1,2",
+		"1,2)",
+		"[1;2)",
+		"[1.5,2)",
+		"[x,2)",
+		"[1,x)",
+		"[-1,2)",
+		"[2,2)",
+		"(1,2)",
+		"[0,4294967297)",
+	}
+	for _, in := range invalid {
+		if got, err := pgrange.ParseNumRange(in, false); err == nil {
+			t.Errorf("ParseNumRange(%q), expected error, got %v", in, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package iprange
+
+import (
+	"math/big"
+	"net/netip"
+	"slices"
+	"sort"
+
+	"github.com/gaissmai/iprange/internal/u128"
+)
+
+// RankIndex answers rank and select queries over a set of addresses,
+// e.g. to shard the address space by equal address weight.
+// It holds the prefix sums of the range sizes, the queries are
+// binary searches. IPv4 addresses sort before IPv6 addresses.
+//
+// A RankIndex is immutable and safe for concurrent use.
+type RankIndex struct {
+	ranges []IPRange
+	sums   []*big.Int // sums[i] is the number of addresses in ranges[:i]
+}
+
+// NewRankIndex returns the index for the addresses in rs.
+// The ranges are merged before, see Merge.
+func NewRankIndex(rs []IPRange) *RankIndex {
+	x := &RankIndex{ranges: Merge(rs)}
+
+	x.sums = make([]*big.Int, 0, len(x.ranges)+1)
+	sum := new(big.Int)
+	x.sums = append(x.sums, new(big.Int))
+	for _, r := range x.ranges {
+		sum.Add(sum, r.size())
+		x.sums = append(x.sums, new(big.Int).Set(sum))
+	}
+	return x
+}
+
+// Ranges returns the merged ranges of the index.
+func (x *RankIndex) Ranges() []IPRange {
+	return slices.Clone(x.ranges)
+}
+
+// Size returns the number of addresses in the set.
+func (x *RankIndex) Size() *big.Int {
+	return new(big.Int).Set(x.sums[len(x.sums)-1])
+}
+
+// Rank returns the number of addresses in the set less than a.
+func (x *RankIndex) Rank(a netip.Addr) *big.Int {
+	// the first range not entirely below a
+	i := sort.Search(len(x.ranges), func(i int) bool {
+		return !x.ranges[i].last.Less(a)
+	})
+
+	rank := new(big.Int).Set(x.sums[i])
+	if i < len(x.ranges) && x.ranges[i].first.Less(a) {
+		off, _ := x.ranges[i].indexOf(a)
+		rank.Add(rank, off.Big())
+	}
+	return rank
+}
+
+// Select returns the k-th address of the set, counting from zero,
+// the reverse of Rank. It returns false if k is negative or not less than Size.
+func (x *RankIndex) Select(k *big.Int) (netip.Addr, bool) {
+	if k.Sign() < 0 || k.Cmp(x.sums[len(x.sums)-1]) >= 0 {
+		return netip.Addr{}, false
+	}
+
+	// the first range ending after k
+	i := sort.Search(len(x.ranges), func(i int) bool {
+		return x.sums[i+1].Cmp(k) > 0
+	})
+
+	return x.ranges[i].AddrAtBig(new(big.Int).Sub(k, x.sums[i]))
+}
+
+// Quantiles returns the n-1 split points dividing the set into n parts of
+// equal address weight, the j-th split point is the address of rank
+// j*Size/n, rounded down. Part j covers the addresses from split point
+// j-1 up to split point j, exclusive.
+// It returns nil if n is less than 2 or the set is empty.
+func (x *RankIndex) Quantiles(n int) []netip.Addr {
+	size := x.sums[len(x.sums)-1]
+	if n < 2 || size.Sign() == 0 {
+		return nil
+	}
+
+	out := make([]netip.Addr, 0, n-1)
+	k := new(big.Int)
+	for j := 1; j < n; j++ {
+		k.Mul(size, big.NewInt(int64(j)))
+		k.Quo(k, big.NewInt(int64(n)))
+
+		a, _ := x.Select(k)
+		out = append(out, a)
+	}
+	return out
+}
+
+// size returns the number of addresses in r.
+func (r IPRange) size() *big.Int {
+	n, _ := u128.From(r.last).Sub(u128.From(r.first))
+	b := n.Big()
+	return b.Add(b, big.NewInt(1))
+}
@@ -0,0 +1,120 @@
+package iprange_test
+
+import (
+	"math/big"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestRankSelect(t *testing.T) {
+	t.Parallel()
+	x := iprange.NewRankIndex([]iprange.IPRange{
+		mustFromString("10.0.1.0/24"),
+		mustFromString("10.0.0.0/24"),
+		mustFromString("192.168.0.0/30"),
+		mustFromString("2001:db8::/127"),
+	})
+
+	if got := x.Size(); got.Cmp(big.NewInt(256+256+4+2)) != 0 {
+		t.Errorf("Size, got %s, want 518", got)
+	}
+	if got := x.Ranges(); len(got) != 3 {
+		t.Errorf("Ranges, got %v, want merged", got)
+	}
+
+	tests := []struct {
+		addr string
+		rank int64
+	}{
+		{"0.0.0.0", 0},
+		{"10.0.0.0", 0},
+		{"10.0.0.1", 1},
+		{"10.0.1.255", 511},
+		{"10.0.2.0", 512},
+		{"192.168.0.2", 514},
+		{"192.168.0.3", 515},
+		{"2001:db8::1", 517},
+		{"2001:db8::2", 518},
+		{"::1", 516},
+	}
+
+	for _, tt := range tests {
+		a := mustParseAddr(tt.addr)
+		if got := x.Rank(a); got.Cmp(big.NewInt(tt.rank)) != 0 {
+			t.Errorf("Rank(%s), got %s, want %d", a, got, tt.rank)
+		}
+	}
+
+	// Select is the reverse of Rank for all addresses in the set
+	for k := range int64(518) {
+		a, ok := x.Select(big.NewInt(k))
+		if !ok {
+			t.Fatalf("Select(%d), not ok", k)
+		}
+		if got := x.Rank(a); got.Cmp(big.NewInt(k)) != 0 {
+			t.Errorf("Rank(Select(%d)), got %s", k, got)
+		}
+	}
+
+	for _, k := range []int64{-1, 518} {
+		if a, ok := x.Select(big.NewInt(k)); ok {
+			t.Errorf("Select(%d), got %s, expected not ok", k, a)
+		}
+	}
+}
+
+func TestRankSelectIPv6(t *testing.T) {
+	t.Parallel()
+	// the count of ::/0 exceeds 128 bits together with the IPv4 space
+	x := iprange.NewRankIndex([]iprange.IPRange{
+		mustFromString("0.0.0.0/0"),
+		mustFromString("::/0"),
+	})
+
+	want := new(big.Int).Lsh(big.NewInt(1), 128)
+	want.Add(want, big.NewInt(1<<32))
+	if got := x.Size(); got.Cmp(want) != 0 {
+		t.Errorf("Size, got %s, want %s", got, want)
+	}
+
+	last := mustParseAddr("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+	k := new(big.Int).Sub(want, big.NewInt(1))
+	if got, ok := x.Select(k); !ok || got != last {
+		t.Errorf("Select(%s), got %s %v, want %s", k, got, ok, last)
+	}
+	if got := x.Rank(last); got.Cmp(k) != 0 {
+		t.Errorf("Rank(%s), got %s, want %s", last, got, k)
+	}
+}
+
+func TestQuantiles(t *testing.T) {
+	t.Parallel()
+	x := iprange.NewRankIndex([]iprange.IPRange{
+		mustFromString("10.0.0.0/24"),
+		mustFromString("10.0.2.0/24"),
+	})
+
+	tests := []struct {
+		n    int
+		want []netip.Addr
+	}{
+		{0, nil},
+		{1, nil},
+		{2, []netip.Addr{mustParseAddr("10.0.2.0")}},
+		{4, []netip.Addr{mustParseAddr("10.0.0.128"), mustParseAddr("10.0.2.0"), mustParseAddr("10.0.2.128")}},
+		{3, []netip.Addr{mustParseAddr("10.0.0.170"), mustParseAddr("10.0.2.85")}},
+	}
+
+	for _, tt := range tests {
+		if got := x.Quantiles(tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("Quantiles(%d), got %v, want %v", tt.n, got, tt.want)
+		}
+	}
+
+	if got := iprange.NewRankIndex(nil).Quantiles(4); got != nil {
+		t.Errorf("Quantiles of empty set, got %v, want nil", got)
+	}
+}
@@ -0,0 +1,255 @@
+// Package rir parses the statistics files of the Regional Internet Registries,
+// the delegated-*-latest and delegated-*-extended-latest files published by
+// AFRINIC, APNIC, ARIN, LACNIC and RIPE NCC.
+//
+// IPv4 records describe blocks as start address and address count,
+// which is frequently not a CIDR prefix, each record is returned as IPRange.
+//
+// File format: https://www.nro.net/wp-content/uploads/nro-extended-stats-readme5.txt
+package rir
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gaissmai/iprange"
+)
+
+// Header is the version line of a statistics file.
+type Header struct {
+	Version   string
+	Registry  string
+	Serial    string
+	Records   int
+	StartDate time.Time
+	EndDate   time.Time
+	UTCOffset string
+}
+
+// Record is an IPv4 or IPv6 record of a statistics file.
+type Record struct {
+	Registry string
+	Country  string // ISO 3166 2-letter code, "ZZ" or empty if not assigned
+	Range    iprange.IPRange
+	Date     time.Time // zero if not given
+	Status   string    // allocated, assigned, available or reserved
+	OpaqueID string    // extended format only
+}
+
+// Reader reads the IPv4 and IPv6 records of a statistics file.
+// Comments, summary lines and ASN records are skipped.
+type Reader struct {
+	// Header is set after the first call to Read, if the file has a version line.
+	Header *Header
+
+	scanner *bufio.Scanner
+	lineNo  int
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{scanner: bufio.NewScanner(r)}
+}
+
+// Read returns the next IPv4 or IPv6 record.
+// At the end of input it returns io.EOF.
+// Errors report the line number of the invalid record.
+func (r *Reader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.lineNo++
+		line := strings.TrimSpace(r.scanner.Text())
+
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Split(line, "|")
+
+		// version line, the first non-comment line
+		if r.Header == nil && len(fields) == 7 && isVersion(fields[0]) {
+			h, err := parseHeader(fields)
+			if err != nil {
+				return Record{}, fmt.Errorf("line %d: %w", r.lineNo, err)
+			}
+			r.Header = &h
+			continue
+		}
+
+		// summary lines, e.g. "apnic|*|ipv4|*|45678|summary"
+		if len(fields) == 6 && fields[5] == "summary" {
+			continue
+		}
+
+		if len(fields) < 7 {
+			return Record{}, fmt.Errorf("line %d: want at least 7 fields, got %d", r.lineNo, len(fields))
+		}
+
+		// other resource types, e.g. asn
+		if fields[2] != "ipv4" && fields[2] != "ipv6" {
+			continue
+		}
+
+		rec, err := parseRecord(fields)
+		if err != nil {
+			return Record{}, fmt.Errorf("line %d: %w", r.lineNo, err)
+		}
+		return rec, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+// ReadAll reads all remaining records.
+func (r *Reader) ReadAll() ([]Record, error) {
+	var out []Record
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, rec)
+	}
+}
+
+// ByCountry groups the ranges of the records by country code
+// and merges them per country, see iprange.Merge.
+// Records without country code are skipped.
+// Use the record status to filter e.g. allocated and assigned space before.
+func ByCountry(recs []Record) map[string][]iprange.IPRange {
+	out := make(map[string][]iprange.IPRange)
+	for _, rec := range recs {
+		if rec.Country == "" {
+			continue
+		}
+		out[rec.Country] = append(out[rec.Country], rec.Range)
+	}
+
+	for cc, rs := range out {
+		out[cc] = iprange.Merge(rs)
+	}
+	return out
+}
+
+// isVersion reports whether s is a format version, e.g. "2" or "2.3".
+func isVersion(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func parseHeader(fields []string) (Header, error) {
+	h := Header{
+		Version:   fields[0],
+		Registry:  fields[1],
+		Serial:    fields[2],
+		UTCOffset: fields[6],
+	}
+
+	var err error
+	if h.Records, err = strconv.Atoi(fields[3]); err != nil {
+		return h, fmt.Errorf("invalid record count %q", fields[3])
+	}
+	if h.StartDate, err = parseDate(fields[4]); err != nil {
+		return h, err
+	}
+	if h.EndDate, err = parseDate(fields[5]); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+func parseRecord(fields []string) (Record, error) {
+	rec := Record{
+		Registry: fields[0],
+		Country:  fields[1],
+		Status:   fields[6],
+	}
+	if len(fields) > 7 {
+		rec.OpaqueID = fields[7]
+	}
+
+	var err error
+	if rec.Date, err = parseDate(fields[5]); err != nil {
+		return rec, err
+	}
+
+	start, err := netip.ParseAddr(fields[3])
+	if err != nil {
+		return rec, err
+	}
+
+	switch fields[2] {
+	case "ipv4":
+		rec.Range, err = rangeFromCount(start, fields[4])
+	case "ipv6":
+		rec.Range, err = rangeFromBits(start, fields[4])
+	}
+	return rec, err
+}
+
+// rangeFromCount returns the IPv4 range of count addresses beginning at start.
+func rangeFromCount(start netip.Addr, value string) (iprange.IPRange, error) {
+	if !start.Is4() {
+		return iprange.IPRange{}, fmt.Errorf("not an IPv4 address: %s", start)
+	}
+
+	count, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || count == 0 {
+		return iprange.IPRange{}, fmt.Errorf("invalid address count %q", value)
+	}
+
+	a4 := start.As4()
+	last := uint64(binary.BigEndian.Uint32(a4[:])) + count - 1
+	if last > math.MaxUint32 {
+		return iprange.IPRange{}, fmt.Errorf("address count %s beyond end of address space", value)
+	}
+
+	binary.BigEndian.PutUint32(a4[:], uint32(last))
+	return iprange.FromAddrs(start, netip.AddrFrom4(a4))
+}
+
+// rangeFromBits returns the IPv6 prefix range of start with length value.
+func rangeFromBits(start netip.Addr, value string) (iprange.IPRange, error) {
+	if !start.Is6() {
+		return iprange.IPRange{}, fmt.Errorf("not an IPv6 address: %s", start)
+	}
+
+	bits, err := strconv.Atoi(value)
+	if err != nil {
+		return iprange.IPRange{}, fmt.Errorf("invalid prefix length %q", value)
+	}
+
+	p, err := start.Prefix(bits)
+	if err != nil {
+		return iprange.IPRange{}, err
+	}
+	if p.Addr() != start {
+		return iprange.IPRange{}, fmt.Errorf("%s/%d has host bits set", start, bits)
+	}
+	return iprange.FromPrefix(p)
+}
+
+// parseDate parses the YYYYMMDD dates, empty and all-zero dates are the zero time.
+func parseDate(s string) (time.Time, error) {
+	if s == "" || strings.Trim(s, "0") == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("20060102", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,129 @@
+package rir_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/rir"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+const delegated = `# comment
+2.3|apnic|20240101|6|19830613|20231231|+1000
+apnic|*|asn|*|1|summary
+apnic|*|ipv4|*|4|summary
+apnic|*|ipv6|*|1|summary
+apnic|JP|asn|173|1|20020801|allocated|A91A7381
+apnic|AU|ipv4|1.0.0.0|256|20110811|assigned|A91872ED
+apnic|CN|ipv4|1.0.1.0|768|20110414|allocated|A92E1062
+apnic|AU|ipv4|1.0.4.0|1024|20110412|allocated|A9192210
+apnic|ZZ|ipv4|1.0.8.0|256||available|
+apnic|AU|ipv6|2001:200::|35|19990813|allocated|A91A7381
+`
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+	rd := rir.NewReader(strings.NewReader(delegated))
+
+	recs, err := rd.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantHeader := rir.Header{
+		Version:   "2.3",
+		Registry:  "apnic",
+		Serial:    "20240101",
+		Records:   6,
+		StartDate: time.Date(1983, 6, 13, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+		UTCOffset: "+1000",
+	}
+	if rd.Header == nil || *rd.Header != wantHeader {
+		t.Errorf("Header, got %+v, want %+v", rd.Header, wantHeader)
+	}
+
+	want := []rir.Record{
+		{"apnic", "AU", mustFromString("1.0.0.0/24"), time.Date(2011, 8, 11, 0, 0, 0, 0, time.UTC), "assigned", "A91872ED"},
+		{"apnic", "CN", mustFromString("1.0.1.0-1.0.3.255"), time.Date(2011, 4, 14, 0, 0, 0, 0, time.UTC), "allocated", "A92E1062"},
+		{"apnic", "AU", mustFromString("1.0.4.0/22"), time.Date(2011, 4, 12, 0, 0, 0, 0, time.UTC), "allocated", "A9192210"},
+		{"apnic", "ZZ", mustFromString("1.0.8.0/24"), time.Time{}, "available", ""},
+		{"apnic", "AU", mustFromString("2001:200::/35"), time.Date(1999, 8, 13, 0, 0, 0, 0, time.UTC), "allocated", "A91A7381"},
+	}
+	if !slices.Equal(recs, want) {
+		t.Errorf("ReadAll\ngot:  %v\nwant: %v", recs, want)
+	}
+}
+
+func TestReaderNonExtended(t *testing.T) {
+	t.Parallel()
+	input := "ripencc|FR|ipv4|2.0.0.0|1048576|20100712|allocated\n"
+
+	recs, err := rir.NewReader(strings.NewReader(input)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Range != mustFromString("2.0.0.0/12") || recs[0].OpaqueID != "" {
+		t.Errorf("ReadAll, got %v", recs)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	t.Parallel()
+	tests := []string{
+		"apnic|AU|ipv4|1.0.0.0\n",
+		"apnic|AU|ipv4|1.0.0|256|20110811|assigned\n",
+		"apnic|AU|ipv4|::1|256|20110811|assigned\n",
+		"apnic|AU|ipv4|1.0.0.0|0|20110811|assigned\n",
+		"apnic|AU|ipv4|1.0.0.0|x|20110811|assigned\n",
+		"apnic|AU|ipv4|255.255.255.0|257|20110811|assigned\n",
+		"apnic|AU|ipv4|1.0.0.0|256|2011-08-11|assigned\n",
+		"apnic|AU|ipv6|1.0.0.0|32|20110811|assigned\n",
+		"apnic|AU|ipv6|2001:200::|x|20110811|assigned\n",
+		"apnic|AU|ipv6|2001:200::|129|20110811|assigned\n",
+		"apnic|AU|ipv6|2001:200::1|32|20110811|assigned\n",
+		"2|apnic|20240101|x|19830613|20231231|+1000\n",
+	}
+
+	for _, input := range tests {
+		_, err := rir.NewReader(strings.NewReader(input)).ReadAll()
+		if err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+			t.Errorf("ReadAll(%q), expected error with line number, got %v", input, err)
+		}
+	}
+}
+
+func TestByCountry(t *testing.T) {
+	t.Parallel()
+	recs, err := rir.NewReader(strings.NewReader(delegated)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := rir.ByCountry(recs)
+
+	want := map[string][]iprange.IPRange{
+		"AU": {mustFromString("1.0.0.0/24"), mustFromString("1.0.4.0/22"), mustFromString("2001:200::/35")},
+		"CN": {mustFromString("1.0.1.0-1.0.3.255")},
+		"ZZ": {mustFromString("1.0.8.0/24")},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ByCountry, got %v, want %v", got, want)
+	}
+	for cc, rs := range want {
+		if !slices.Equal(got[cc], rs) {
+			t.Errorf("ByCountry[%s], got %v, want %v", cc, got[cc], rs)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package rpsl reads inetnum and inet6num objects from RPSL database dumps,
+// as published by RIPE NCC, APNIC and AFRINIC, e.g. ripe.db.inetnum.
+//
+// The address ranges of the objects are returned as IPRange, inetnum
+// values like "193.0.0.0 - 193.0.7.255" with the spaces around the hyphen
+// are accepted, as well as CIDR prefixes.
+package rpsl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/internal/rangetext"
+)
+
+// Attr is an attribute of an RPSL object, continuation lines are joined
+// with a single space, end-of-line comments are removed.
+type Attr struct {
+	Key   string // lowercase
+	Value string
+}
+
+// Object is an inetnum or inet6num object.
+type Object struct {
+	Class string // "inetnum" or "inet6num"
+	Range iprange.IPRange
+
+	// frequently used attributes
+	Netname string
+	Country string
+	Status  string
+	MntBy   []string
+
+	// all attributes, in input order, including the class attribute
+	Attrs []Attr
+}
+
+// Get returns the value of the first attribute with the key, or the empty string.
+func (o Object) Get(key string) string {
+	for _, a := range o.Attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// GetAll returns the values of all attributes with the key.
+func (o Object) GetAll(key string) []string {
+	var out []string
+	for _, a := range o.Attrs {
+		if a.Key == key {
+			out = append(out, a.Value)
+		}
+	}
+	return out
+}
+
+// Reader reads the inetnum and inet6num objects of an RPSL stream.
+// Objects of other classes are skipped.
+type Reader struct {
+	scanner *bufio.Scanner
+	lineNo  int
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{scanner: bufio.NewScanner(r)}
+}
+
+// Read returns the next inetnum or inet6num object.
+// At the end of input it returns io.EOF.
+// Errors report the line number of the invalid object.
+func (r *Reader) Read() (Object, error) {
+	for {
+		attrs, lineNo, err := r.readObject()
+		if err != nil {
+			return Object{}, err
+		}
+
+		class := attrs[0].Key
+		if class != "inetnum" && class != "inet6num" {
+			continue
+		}
+
+		obj, err := newObject(attrs)
+		if err != nil {
+			return Object{}, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		return obj, nil
+	}
+}
+
+// ReadAll reads all remaining inetnum and inet6num objects.
+func (r *Reader) ReadAll() ([]Object, error) {
+	var out []Object
+	for {
+		obj, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, obj)
+	}
+}
+
+// readObject returns the attributes of the next object, of any class,
+// and the line number of its first line.
+func (r *Reader) readObject() (attrs []Attr, lineNo int, err error) {
+	for r.scanner.Scan() {
+		r.lineNo++
+		line := r.scanner.Text()
+
+		// comments, e.g. the dump headers
+		if strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// blank line terminates the object
+		if strings.TrimSpace(line) == "" {
+			if len(attrs) > 0 {
+				return attrs, lineNo, nil
+			}
+			continue
+		}
+
+		// continuation line
+		if c := line[0]; c == ' ' || c == '\t' || c == '+' {
+			if len(attrs) == 0 {
+				return nil, r.lineNo, fmt.Errorf("line %d: continuation line without attribute", r.lineNo)
+			}
+			cont := stripComment(line[1:])
+			if cont != "" {
+				last := &attrs[len(attrs)-1]
+				last.Value = strings.TrimSpace(last.Value + " " + cont)
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, r.lineNo, fmt.Errorf("line %d: missing colon in attribute %q", r.lineNo, line)
+		}
+
+		if len(attrs) == 0 {
+			lineNo = r.lineNo
+		}
+		attrs = append(attrs, Attr{
+			Key:   strings.ToLower(strings.TrimSpace(key)),
+			Value: stripComment(value),
+		})
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, r.lineNo, err
+	}
+	if len(attrs) > 0 {
+		return attrs, lineNo, nil
+	}
+	return nil, r.lineNo, io.EOF
+}
+
+// newObject builds the object from the attributes, the first is the class attribute.
+func newObject(attrs []Attr) (Object, error) {
+	obj := Object{
+		Class: attrs[0].Key,
+		Attrs: attrs,
+	}
+
+	var err error
+	if obj.Range, err = ParseRange(attrs[0].Value); err != nil {
+		return obj, fmt.Errorf("%s: %w", obj.Class, err)
+	}
+
+	obj.Netname = obj.Get("netname")
+	obj.Country = obj.Get("country")
+	obj.Status = obj.Get("status")
+	obj.MntBy = obj.GetAll("mnt-by")
+
+	return obj, nil
+}
+
+// ParseRange parses the value of an inetnum or inet6num attribute,
+// e.g. "193.0.0.0 - 193.0.7.255" or "2001:db8::/32".
+// Unlike iprange.FromString, spaces around the hyphen are accepted.
+func ParseRange(s string) (iprange.IPRange, error) {
+	return rangetext.Parse(s)
+}
+
+// stripComment removes the end-of-line comment and surrounding whitespace.
+func stripComment(s string) string {
+	s, _, _ = strings.Cut(s, "#")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,136 @@
+package rpsl_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/rpsl"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+const dump = `% This is the RIPE Database dump.
+% The objects are in RPSL format.
+
+inetnum:        193.0.0.0 - 193.0.7.255
+netname:        RIPE-NCC
+descr:          RIPE Network Coordination Centre
+                Amsterdam, Netherlands
+country:        NL
+status:         ASSIGNED PA   # end-of-line comment
+mnt-by:         RIPE-NCC-MNT
+mnt-by:         RIPE-NCC-HM-MNT
+source:         RIPE
+
+aut-num:        AS3333
+as-name:        RIPE-NCC-AS
+
+inet6num:       2001:67c:2e8::/48
+netname:        RIPE-NCC
+descr:          RIPE Network Coordination Centre
++               second line
+country:        NL
+status:         ASSIGNED
+mnt-by:         RIPE-NCC-MNT
+source:         RIPE
+inetnum: 10.0.0.3-10.0.17.134
+NetName: ODD
+`
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+	objs, err := rpsl.NewReader(strings.NewReader(dump)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(objs) != 2 {
+		t.Fatalf("ReadAll, got %d objects, want 2", len(objs))
+	}
+
+	v4 := objs[0]
+	if v4.Class != "inetnum" || v4.Range != mustFromString("193.0.0.0/21") {
+		t.Errorf("inetnum, got %s %s", v4.Class, v4.Range)
+	}
+	if v4.Netname != "RIPE-NCC" || v4.Country != "NL" || v4.Status != "ASSIGNED PA" {
+		t.Errorf("inetnum attributes, got %q %q %q", v4.Netname, v4.Country, v4.Status)
+	}
+	if !slices.Equal(v4.MntBy, []string{"RIPE-NCC-MNT", "RIPE-NCC-HM-MNT"}) {
+		t.Errorf("inetnum mnt-by, got %q", v4.MntBy)
+	}
+	if got := v4.Get("descr"); got != "RIPE Network Coordination Centre Amsterdam, Netherlands" {
+		t.Errorf("inetnum descr, got %q", got)
+	}
+	if got := v4.Get("remarks"); got != "" {
+		t.Errorf("inetnum remarks, got %q, want empty", got)
+	}
+
+	// an object is terminated by a blank line, so the last inetnum
+	// attribute belongs to the inet6num object
+	v6 := objs[1]
+	if v6.Class != "inet6num" || v6.Range != mustFromString("2001:67c:2e8::/48") {
+		t.Errorf("inet6num, got %s %s", v6.Class, v6.Range)
+	}
+	if got := v6.Get("descr"); got != "RIPE Network Coordination Centre second line" {
+		t.Errorf("inet6num descr, got %q", got)
+	}
+	if got := v6.GetAll("inetnum"); !slices.Equal(got, []string{"10.0.0.3-10.0.17.134"}) {
+		t.Errorf("inet6num inetnum, got %q", got)
+	}
+	if got := v6.Get("netname"); got != "RIPE-NCC" {
+		t.Errorf("inet6num netname, got %q", got)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	t.Parallel()
+	tests := []string{
+		"inetnum: 193.0.7.255 - 193.0.0.0\n",
+		"inetnum: 193.0.0.0 - ::1\n",
+		"inet6num: 2001:db8::/129\n",
+		"  continuation first\n",
+		"\n\ninetnum 193.0.0.0\n",
+	}
+
+	for _, input := range tests {
+		if objs, err := rpsl.NewReader(strings.NewReader(input)).ReadAll(); err == nil {
+			t.Errorf("ReadAll(%q), expected error, got %v", input, objs)
+		}
+	}
+
+	_, err := rpsl.NewReader(strings.NewReader("\n\ninetnum: 10.0.0.9 - 10.0.0.1\n")).Read()
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("Read, expected error with line 3, got %v", err)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want iprange.IPRange
+	}{
+		{"193.0.0.0 - 193.0.7.255", mustFromString("193.0.0.0/21")},
+		{"  10.0.0.3  -  10.0.17.134 ", mustFromString("10.0.0.3-10.0.17.134")},
+		{"10.0.0.0/8", mustFromString("10.0.0.0/8")},
+		{"2001:db8:: - 2001:db8::ff", mustFromString("2001:db8::/120")},
+	}
+
+	for _, tt := range tests {
+		got, err := rpsl.ParseRange(tt.in)
+		if err != nil {
+			t.Fatalf("ParseRange(%q) failed: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseRange(%q), got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package iprange
+
+import (
+	"cmp"
+	"iter"
+	"net/netip"
+	"slices"
+)
+
+// Segment is an elementary segment of overlapping ranges, see Segments.
+type Segment struct {
+	Range IPRange
+
+	// Sources are the indices of the input ranges covering Range,
+	// in ascending order.
+	Sources []int
+
+	// Depth is the number of covering input ranges, len(Sources).
+	Depth int
+}
+
+// segmentEvent is the start of a range at addr or its end after addr.
+type segmentEvent struct {
+	addr netip.Addr
+	end  bool
+	idx  int
+}
+
+// Segments returns an iterator over the disjoint elementary segments of the
+// ranges, sorted in ascending order. All addresses of a segment are covered
+// by the same input ranges, addresses not covered by any range are skipped.
+// Invalid ranges are ignored.
+//
+// It is a sweep line over the start and end events of the ranges,
+// Merge is the special case joining all segments with depth > 0.
+func Segments(rs []IPRange) iter.Seq[Segment] {
+	return func(yield func(Segment) bool) {
+		events := make([]segmentEvent, 0, 2*len(rs))
+		for i, r := range rs {
+			if r == zeroValue {
+				continue
+			}
+			events = append(events,
+				segmentEvent{addr: r.first, idx: i},
+				segmentEvent{addr: r.last, end: true, idx: i},
+			)
+		}
+
+		// starts at an address before the ends after it
+		slices.SortFunc(events, func(a, b segmentEvent) int {
+			if c := a.addr.Compare(b.addr); c != 0 {
+				return c
+			}
+			if a.end != b.end {
+				if a.end {
+					return 1
+				}
+				return -1
+			}
+			return cmp.Compare(a.idx, b.idx)
+		})
+
+		var active []int   // sorted input indices
+		var cur netip.Addr // start of the pending segment
+
+		emit := func(last netip.Addr) bool {
+			return yield(Segment{
+				Range:   IPRange{cur, last},
+				Sources: slices.Clone(active),
+				Depth:   len(active),
+			})
+		}
+
+		for _, ev := range events {
+			if !ev.end {
+				if len(active) > 0 && cur.Less(ev.addr) {
+					if !emit(ev.addr.Prev()) {
+						return
+					}
+				}
+				if len(active) == 0 || cur.Less(ev.addr) {
+					cur = ev.addr
+				}
+
+				i, _ := slices.BinarySearch(active, ev.idx)
+				active = slices.Insert(active, i, ev.idx)
+				continue
+			}
+
+			// cur is invalid past the end of the address space
+			if cur.IsValid() && cur.Compare(ev.addr) <= 0 {
+				if !emit(ev.addr) {
+					return
+				}
+				cur = ev.addr.Next()
+			}
+
+			i, _ := slices.BinarySearch(active, ev.idx)
+			active = slices.Delete(active, i, i+1)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package iprange_test
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+func TestSegments(t *testing.T) {
+	t.Parallel()
+	rs := []iprange.IPRange{
+		mustFromString("10.0.0.0-10.0.0.9"),   // 0
+		mustFromString("10.0.0.5-10.0.0.14"),  // 1
+		mustFromString("10.0.0.5"),            // 2
+		{},                                    // 3, ignored
+		mustFromString("10.0.0.20-10.0.0.29"), // 4
+		mustFromString("255.255.255.255"),     // 5
+		mustFromString("::/0"),                // 6
+		mustFromString("ffff::/16"),           // 7
+	}
+
+	want := []iprange.Segment{
+		{Range: mustFromString("10.0.0.0-10.0.0.4"), Sources: []int{0}, Depth: 1},
+		{Range: mustFromString("10.0.0.5"), Sources: []int{0, 1, 2}, Depth: 3},
+		{Range: mustFromString("10.0.0.6-10.0.0.9"), Sources: []int{0, 1}, Depth: 2},
+		{Range: mustFromString("10.0.0.10-10.0.0.14"), Sources: []int{1}, Depth: 1},
+		{Range: mustFromString("10.0.0.20-10.0.0.29"), Sources: []int{4}, Depth: 1},
+		{Range: mustFromString("255.255.255.255"), Sources: []int{5}, Depth: 1},
+		{Range: mustFromString("::-fffe:ffff:ffff:ffff:ffff:ffff:ffff:ffff"), Sources: []int{6}, Depth: 1},
+		{Range: mustFromString("ffff::/16"), Sources: []int{6, 7}, Depth: 2},
+	}
+
+	got := slices.Collect(iprange.Segments(rs))
+	if !slices.EqualFunc(got, want, equalSegment) {
+		t.Errorf("Segments, got %v, want %v", got, want)
+	}
+
+	// early stop
+	var n int
+	for range iprange.Segments(rs) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("Segments, early stop got %d iterations", n)
+	}
+
+	if got := slices.Collect(iprange.Segments(nil)); got != nil {
+		t.Errorf("Segments(nil), got %v, want nil", got)
+	}
+}
+
+// TestSegmentsBruteForce compares the segments with the coverage of each address.
+func TestSegmentsBruteForce(t *testing.T) {
+	t.Parallel()
+	prng := rand.New(rand.NewPCG(42, 42))
+
+	for range 20 {
+		var rs []iprange.IPRange
+		for range 20 {
+			a, b := prng.IntN(256), prng.IntN(256)
+			r, _ := iprange.FromAddrs(
+				netip.AddrFrom4([4]byte{10, 0, 0, byte(min(a, b))}),
+				netip.AddrFrom4([4]byte{10, 0, 0, byte(max(a, b))}),
+			)
+			rs = append(rs, r)
+		}
+
+		segs := slices.Collect(iprange.Segments(rs))
+
+		// each address is in the segment with exactly its covering ranges
+		for x := range 256 {
+			addr := netip.AddrFrom4([4]byte{10, 0, 0, byte(x)})
+
+			var cover []int
+			for i, r := range rs {
+				if first, last := r.Addrs(); first.Compare(addr) <= 0 && addr.Compare(last) <= 0 {
+					cover = append(cover, i)
+				}
+			}
+
+			var in []iprange.Segment
+			for _, s := range segs {
+				if first, last := s.Range.Addrs(); first.Compare(addr) <= 0 && addr.Compare(last) <= 0 {
+					in = append(in, s)
+				}
+			}
+
+			switch {
+			case cover == nil && len(in) != 0:
+				t.Fatalf("Segments(%v), uncovered %s in %v", rs, addr, in)
+			case cover != nil && (len(in) != 1 || !slices.Equal(in[0].Sources, cover) || in[0].Depth != len(cover)):
+				t.Fatalf("Segments(%v), %s in %v, want sources %v", rs, addr, in, cover)
+			}
+		}
+
+		// the union of the segments is Merge
+		var ranges []iprange.IPRange
+		for _, s := range segs {
+			ranges = append(ranges, s.Range)
+		}
+		if got, want := iprange.Merge(ranges), iprange.Merge(rs); !slices.Equal(got, want) {
+			t.Fatalf("Segments(%v), union %v, want %v", rs, got, want)
+		}
+	}
+}
+
+func equalSegment(a, b iprange.Segment) bool {
+	return a.Range == b.Range && a.Depth == b.Depth && slices.Equal(a.Sources, b.Sources)
+}
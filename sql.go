@@ -0,0 +1,180 @@
+package iprange
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+// Value implements driver.Valuer.
+// It stores r in its text representation as returned by String,
+// suitable for text columns and for PostgreSQL cidr columns if r is a prefix.
+// The zero value is stored as NULL.
+func (r IPRange) Value() (driver.Value, error) {
+	if !r.IsValid() {
+		return nil, nil
+	}
+	return r.String(), nil
+}
+
+// Scan implements sql.Scanner.
+//
+// A string is parsed by FromString, this includes the text output of
+// PostgreSQL inet and cidr columns. An inet value with host bits set,
+// e.g. "192.168.1.5/24", is scanned as the network range 192.168.1.0/24.
+//
+// A []byte is parsed as text, if this fails it is decoded by UnmarshalBinary.
+//
+// NULL and empty values are scanned as the zero value.
+// Unlike UnmarshalText, Scan overwrites a non-zero receiver,
+// database/sql reuses the scan destinations for each row.
+func (r *IPRange) Scan(src any) error {
+	if r == nil {
+		return errors.New("Scan on nil receiver")
+	}
+
+	var res IPRange
+	switch v := src.(type) {
+	case nil:
+		// NULL
+	case string:
+		if v != "" {
+			var err error
+			if res, err = FromString(v); err != nil {
+				return err
+			}
+		}
+	case []byte:
+		if len(v) != 0 {
+			var err error
+			if res, err = FromString(string(v)); err != nil {
+				var bin IPRange
+				if bin.UnmarshalBinary(v) != nil {
+					return err
+				}
+				res = bin
+			}
+		}
+	default:
+		return fmt.Errorf("cannot scan %T into IPRange", src)
+	}
+
+	*r = res
+	return nil
+}
+
+// ValueBounds returns the driver values of the first and last address of r,
+// for schemas that store the range boundaries in two separate columns.
+// The zero value is stored as two NULLs.
+func (r IPRange) ValueBounds() (first, last driver.Value) {
+	if !r.IsValid() {
+		return nil, nil
+	}
+	return r.first.String(), r.last.String()
+}
+
+// ScanBounds returns two sql.Scanner destinations for schemas that store
+// the range boundaries in two separate columns, e.g.:
+//
+//	first, last := r.ScanBounds()
+//	err := row.Scan(first, last)
+//
+// The columns may hold the addresses as text, or as raw 4 or 16 bytes.
+// When both columns are scanned, r is set to the range between them.
+// If both columns are NULL, r is set to the zero value.
+// The destinations may be reused for the next row, e.g. in a rows.Next loop.
+func (r *IPRange) ScanBounds() (first, last sql.Scanner) {
+	b := &bounds{r: r}
+	return boundScanner{b, 0}, boundScanner{b, 1}
+}
+
+// bounds collects the two columns scanned by ScanBounds.
+type bounds struct {
+	r       *IPRange
+	addrs   [2]netip.Addr
+	scanned [2]bool
+}
+
+func (b *bounds) reset() {
+	b.addrs = [2]netip.Addr{}
+	b.scanned = [2]bool{}
+}
+
+// boundScanner scans the first (0) or last (1) column of bounds.
+type boundScanner struct {
+	b   *bounds
+	idx int
+}
+
+// Scan implements sql.Scanner.
+func (s boundScanner) Scan(src any) error {
+	if s.b.r == nil {
+		return errors.New("Scan on nil receiver")
+	}
+
+	b := s.b
+
+	addr, err := scanAddr(src)
+	if err != nil {
+		b.reset()
+		return err
+	}
+
+	b.addrs[s.idx] = addr
+	b.scanned[s.idx] = true
+
+	if !b.scanned[0] || !b.scanned[1] {
+		return nil
+	}
+
+	// the pair is complete, ready for the next row
+	first, last := b.addrs[0], b.addrs[1]
+	b.reset()
+
+	if !first.IsValid() && !last.IsValid() {
+		*b.r = zeroValue
+		return nil
+	}
+
+	res, err := FromAddrs(first, last)
+	if err != nil {
+		return err
+	}
+
+	*b.r = res
+	return nil
+}
+
+// scanAddr converts a single address column, NULL is returned as invalid netip.Addr.
+// Raw bytes are only tried if the column is not parsable as text.
+func scanAddr(src any) (netip.Addr, error) {
+	switch v := src.(type) {
+	case nil:
+		return netip.Addr{}, nil
+	case string:
+		return parseAddr(v)
+	case []byte:
+		addr, err := parseAddr(string(v))
+		if err != nil && (len(v) == 4 || len(v) == 16) {
+			addr, _ = netip.AddrFromSlice(v)
+			return addr, nil
+		}
+		return addr, err
+	default:
+		return netip.Addr{}, fmt.Errorf("cannot scan %T into netip.Addr", src)
+	}
+}
+
+// parseAddr parses a single address, also in PostgreSQL inet notation with full-length mask.
+func parseAddr(s string) (netip.Addr, error) {
+	if p, err := netip.ParsePrefix(s); err == nil {
+		if !p.IsSingleIP() {
+			return netip.Addr{}, fmt.Errorf("not a single address: %s", s)
+		}
+		return p.Addr(), nil
+	}
+
+	return netip.ParseAddr(s)
+}
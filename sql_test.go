@@ -0,0 +1,284 @@
+package iprange_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+)
+
+// fakeDriver is a minimal in-memory database/sql driver.
+// Exec appends the arguments as a row, Query returns all rows.
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows [][]driver.Value
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct{ d *fakeDriver }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.rows = append(s.d.rows, slices.Clone(args))
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	return &fakeRows{rows: slices.Clone(s.d.rows)}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// openFakeDB registers a new fake driver and returns a DB connected to it.
+func openFakeDB(t *testing.T) *sql.DB {
+	t.Helper()
+	name := "fake-" + t.Name()
+	sql.Register(name, &fakeDriver{})
+
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLRoundtrip(t *testing.T) {
+	t.Parallel()
+	db := openFakeDB(t)
+
+	want := []iprange.IPRange{
+		mustFromString("10.0.0.0/8"),
+		mustFromString("10.0.0.3-10.0.17.134"),
+		mustFromString("2001:db8::1-2001:db8::f6"),
+		{},
+	}
+
+	for _, r := range want {
+		if _, err := db.Exec("INSERT", r); err != nil {
+			t.Fatalf("Exec(%v) failed: %v", r, err)
+		}
+	}
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []iprange.IPRange
+	for rows.Next() {
+		var r iprange.IPRange
+		if err := rows.Scan(&r); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("SQL roundtrip, got %v, want %v", got, want)
+	}
+}
+
+func TestSQLBoundsRoundtrip(t *testing.T) {
+	t.Parallel()
+	db := openFakeDB(t)
+
+	want := []iprange.IPRange{
+		mustFromString("10.0.0.3-10.0.17.134"),
+		mustFromString("2001:db8::/32"),
+		{},
+	}
+
+	for _, r := range want {
+		first, last := r.ValueBounds()
+		if _, err := db.Exec("INSERT", first, last); err != nil {
+			t.Fatalf("Exec(%v) failed: %v", r, err)
+		}
+	}
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []iprange.IPRange
+	for rows.Next() {
+		r := mustFromString("1.2.3.4") // non-zero, must be overwritten
+		first, last := r.ScanBounds()
+		if err := rows.Scan(first, last); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("SQL bounds roundtrip, got %v, want %v", got, want)
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	binary, _ := mustFromString("10.0.0.1-10.0.0.9").MarshalBinary()
+
+	tests := []struct {
+		src  any
+		want iprange.IPRange
+	}{
+		{nil, iprange.IPRange{}},
+		{"", iprange.IPRange{}},
+		{[]byte{}, iprange.IPRange{}},
+		{"10.0.0.1-10.0.0.9", mustFromString("10.0.0.1-10.0.0.9")},
+		{[]byte("2001:db8::/32"), mustFromString("2001:db8::/32")},
+		{binary, mustFromString("10.0.0.1-10.0.0.9")},
+		{[]byte{10, 0, 0, 1, 10, 0, 0, 9}, mustFromString("10.0.0.1-10.0.0.9")},
+		// PostgreSQL inet and cidr text output
+		{"192.168.1.5/24", mustFromString("192.168.1.0/24")},
+		{"192.168.1.5", mustFromString("192.168.1.5/32")},
+		{"::ffff:1.2.3.0/120", mustFromString("::ffff:1.2.3.0/120")},
+	}
+
+	for _, tt := range tests {
+		r := mustFromString("1.2.3.4") // non-zero, must be overwritten
+		if err := r.Scan(tt.src); err != nil {
+			t.Fatalf("Scan(%v) failed: %v", tt.src, err)
+		}
+		if r != tt.want {
+			t.Errorf("Scan(%v), got %v, want %v", tt.src, r, tt.want)
+		}
+	}
+
+	for _, src := range []any{42, "10.0.0.9-10.0.0.1", []byte("1.2.3"), []byte{1, 2, 3}} {
+		var r iprange.IPRange
+		if err := r.Scan(src); err == nil {
+			t.Errorf("Scan(%v), expected error, got %v", src, r)
+		}
+	}
+
+	var nilRange *iprange.IPRange
+	if err := nilRange.Scan("1.2.3.4"); err == nil {
+		t.Errorf("expected error when Scan on nil receiver, got nil")
+	}
+}
+
+func TestScanBounds(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		first, last any
+		want        iprange.IPRange
+		ok          bool
+	}{
+		{"10.0.0.1", "10.0.0.9", mustFromString("10.0.0.1-10.0.0.9"), true},
+		{[]byte("10.0.0.1"), []byte("10.0.0.9/32"), mustFromString("10.0.0.1-10.0.0.9"), true},
+		{[]byte{10, 0, 0, 1}, []byte{10, 0, 0, 9}, mustFromString("10.0.0.1-10.0.0.9"), true},
+		{[]byte("2001:db8::1:2:34"), "2001:db8::1:2:ff", mustFromString("2001:db8::1:2:34-2001:db8::1:2:ff"), true},
+		{nil, nil, iprange.IPRange{}, true},
+		{nil, "10.0.0.9", iprange.IPRange{}, false},
+		{"10.0.0.9", "10.0.0.1", iprange.IPRange{}, false},
+		{"10.0.0.0/24", "10.0.0.9", iprange.IPRange{}, false},
+		{"10.0.0.1", "::1", iprange.IPRange{}, false},
+		{42, "10.0.0.9", iprange.IPRange{}, false},
+	}
+
+	for _, tt := range tests {
+		var r iprange.IPRange
+		first, last := r.ScanBounds()
+
+		// scan in reverse column order
+		err := last.Scan(tt.last)
+		if err == nil {
+			err = first.Scan(tt.first)
+		}
+
+		if (err == nil) != tt.ok {
+			t.Fatalf("ScanBounds(%v, %v), got err: %v, want ok: %v", tt.first, tt.last, err, tt.ok)
+		}
+		if r != tt.want {
+			t.Errorf("ScanBounds(%v, %v), got %v, want %v", tt.first, tt.last, r, tt.want)
+		}
+	}
+}
+
+func TestScanBoundsReuse(t *testing.T) {
+	t.Parallel()
+	var r iprange.IPRange
+	first, last := r.ScanBounds()
+
+	rows := []struct {
+		first, last any
+		want        iprange.IPRange
+	}{
+		{"10.0.0.1", "10.0.0.9", mustFromString("10.0.0.1-10.0.0.9")},
+		{"192.168.0.0", "192.168.0.255", mustFromString("192.168.0.0/24")},
+		{nil, nil, iprange.IPRange{}},
+		{"2001:db8::", "2001:db8::ff", mustFromString("2001:db8::/120")},
+	}
+
+	for _, row := range rows {
+		if err := first.Scan(row.first); err != nil {
+			t.Fatal(err)
+		}
+		if err := last.Scan(row.last); err != nil {
+			t.Fatalf("ScanBounds(%v, %v) on reused destinations failed: %v", row.first, row.last, err)
+		}
+		if r != row.want {
+			t.Errorf("ScanBounds(%v, %v) on reused destinations, got %v, want %v", row.first, row.last, r, row.want)
+		}
+	}
+
+	// after an error the destinations are reusable too
+	if err := first.Scan(42); err == nil {
+		t.Fatalf("Scan(42), expected error")
+	}
+	if err := last.Scan("10.0.0.9"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Scan("10.0.0.1"); err != nil || r != mustFromString("10.0.0.1-10.0.0.9") {
+		t.Errorf("ScanBounds after error, got %v %v, want 10.0.0.1-10.0.0.9", r, err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package transition maps IPv4 ranges into the IPv6 address space of
+// transition mechanisms and extracts the embedded IPv4 addresses back:
+//
+//   - NAT64 prefixes of RFC 6052, e.g. the well-known prefix 64:ff9b::/96
+//   - 6to4 of RFC 3056, 2002::/16
+//   - Teredo of RFC 4380, 2001::/32, addresses only
+//
+// An IPv4 prefix maps to the IPv6 prefix with the embedded prefix bits,
+// the bits after the embedded address are not restricted.
+// The u-octet, bits 64 to 71 of RFC 6052 addresses, is zero if the
+// embedded address spans it. Therefore the image of an IPv4 range is a
+// single IPv6 range for the /32, /64 and /96 NAT64 prefixes and 6to4,
+// but may consist of several ranges for the /40, /48 and /56 NAT64 prefixes.
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/gaissmai/iprange"
+)
+
+var (
+	// WellKnownPrefix is the NAT64 Well-Known Prefix of RFC 6052.
+	WellKnownPrefix = netip.MustParsePrefix("64:ff9b::/96")
+
+	// SixToFourPrefix is the 6to4 prefix of RFC 3056.
+	SixToFourPrefix = netip.MustParsePrefix("2002::/16")
+
+	// TeredoPrefix is the Teredo prefix of RFC 4380.
+	TeredoPrefix = netip.MustParsePrefix("2001::/32")
+)
+
+// embedding describes where the IPv4 address is embedded in the IPv6 address.
+type embedding struct {
+	prefix [16]byte
+	bits   int
+	idx    [4]int // byte positions of the IPv4 bytes
+}
+
+// newEmbedding returns the embedding for a NAT64 prefix of RFC 6052.
+func newEmbedding(pfx netip.Prefix) (embedding, error) {
+	if !pfx.IsValid() || !pfx.Addr().Is6() || pfx.Addr().Is4In6() {
+		return embedding{}, fmt.Errorf("invalid NAT64 prefix %s", pfx)
+	}
+	if pfx != pfx.Masked() {
+		return embedding{}, fmt.Errorf("NAT64 prefix %s has host bits set", pfx)
+	}
+
+	switch pfx.Bits() {
+	case 32, 40, 48, 56, 64, 96:
+	default:
+		return embedding{}, fmt.Errorf("invalid NAT64 prefix length %d, want 32, 40, 48, 56, 64 or 96", pfx.Bits())
+	}
+
+	e := embedding{prefix: pfx.Addr().As16(), bits: pfx.Bits()}
+	if e.prefix[8] != 0 {
+		return embedding{}, fmt.Errorf("NAT64 prefix %s, bits 64 to 71 must be zero", pfx)
+	}
+
+	// skip the u-octet
+	j := pfx.Bits() / 8
+	for i := range e.idx {
+		if j == 8 {
+			j++
+		}
+		e.idx[i] = j
+		j++
+	}
+	return e, nil
+}
+
+var sixToFour = embedding{
+	prefix: SixToFourPrefix.Addr().As16(),
+	bits:   16,
+	idx:    [4]int{2, 3, 4, 5},
+}
+
+// image returns the IPv6 prefix with the embedded IPv4 prefix p.
+func (e embedding) image(p netip.Prefix) netip.Prefix {
+	if p.Bits() == 0 {
+		return netip.PrefixFrom(netip.AddrFrom16(e.prefix), e.bits)
+	}
+
+	a := e.prefix
+	v4 := p.Addr().As4()
+	for i, j := range e.idx {
+		a[j] = v4[i]
+	}
+
+	// the bit after the last embedded prefix bit
+	n := p.Bits() - 1
+	bits := e.idx[n/8]*8 + n%8 + 1
+
+	return netip.PrefixFrom(netip.AddrFrom16(a), bits).Masked()
+}
+
+// mapRange returns the merged images of the prefixes of the IPv4 range r.
+func (e embedding) mapRange(r iprange.IPRange) ([]iprange.IPRange, error) {
+	if first, _ := r.Addrs(); !first.Is4() {
+		return nil, fmt.Errorf("not an IPv4 range: %s", r)
+	}
+
+	var out []iprange.IPRange
+	for p := range r.Prefixes() {
+		rng, err := iprange.FromPrefix(e.image(p))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rng)
+	}
+	return iprange.Merge(out), nil
+}
+
+// extract returns the IPv4 range of the addresses whose images overlap
+// the IPv6 range r.
+func (e embedding) extract(r iprange.IPRange) (iprange.IPRange, error) {
+	first, last := r.Addrs()
+	if !first.Is6() {
+		return iprange.IPRange{}, fmt.Errorf("not an IPv6 range: %s", r)
+	}
+
+	// the images of the IPv4 addresses are disjoint and ascending,
+	// the images overlapping r are consecutive
+	hostImage := func(i uint64) (netip.Addr, netip.Addr) {
+		img, _ := iprange.FromPrefix(e.image(netip.PrefixFrom(addr4(i), 32)))
+		return img.Addrs()
+	}
+
+	lo := search4(func(i uint64) bool {
+		_, imgLast := hostImage(i)
+		return imgLast.Compare(first) >= 0
+	})
+	end := search4(func(i uint64) bool {
+		imgFirst, _ := hostImage(i)
+		return imgFirst.Compare(last) > 0
+	})
+
+	if lo >= end {
+		return iprange.IPRange{}, errors.New("no embedded IPv4 address in range")
+	}
+
+	return iprange.FromAddrs(addr4(lo), addr4(end-1))
+}
+
+// NAT64 returns the IPv6 ranges of the IPv4 range r embedded in the
+// NAT64 prefix pfx of RFC 6052, merged and sorted.
+// The prefix length must be 32, 40, 48, 56, 64 or 96.
+func NAT64(pfx netip.Prefix, r iprange.IPRange) ([]iprange.IPRange, error) {
+	e, err := newEmbedding(pfx)
+	if err != nil {
+		return nil, err
+	}
+	return e.mapRange(r)
+}
+
+// ExtractNAT64 returns the IPv4 range embedded in the IPv6 range r under
+// the NAT64 prefix pfx, the reverse of NAT64. Addresses of r outside of
+// the images of IPv4 addresses are ignored.
+func ExtractNAT64(pfx netip.Prefix, r iprange.IPRange) (iprange.IPRange, error) {
+	e, err := newEmbedding(pfx)
+	if err != nil {
+		return iprange.IPRange{}, err
+	}
+	return e.extract(r)
+}
+
+// SixToFour returns the 6to4 range of the IPv4 range r,
+// e.g. 2002:c000:200::/40 for 192.0.2.0/24.
+func SixToFour(r iprange.IPRange) (iprange.IPRange, error) {
+	rs, err := sixToFour.mapRange(r)
+	if err != nil {
+		return iprange.IPRange{}, err
+	}
+	return rs[0], nil
+}
+
+// ExtractSixToFour returns the IPv4 range embedded in the 6to4 range r,
+// the reverse of SixToFour.
+func ExtractSixToFour(r iprange.IPRange) (iprange.IPRange, error) {
+	return sixToFour.extract(r)
+}
+
+// Teredo returns the server and the client IPv4 address of the Teredo
+// address a, ok is false if a is not in 2001::/32.
+func Teredo(a netip.Addr) (server, client netip.Addr, ok bool) {
+	if !a.Is6() || !TeredoPrefix.Contains(a) {
+		return server, client, false
+	}
+
+	b := a.As16()
+	server = netip.AddrFrom4([4]byte{b[4], b[5], b[6], b[7]})
+	client = netip.AddrFrom4([4]byte{^b[12], ^b[13], ^b[14], ^b[15]})
+	return server, client, true
+}
+
+// search4 is sort.Search over all 1<<32 IPv4 addresses,
+// the index space overflows int on 32-bit platforms.
+func search4(f func(uint64) bool) uint64 {
+	lo, hi := uint64(0), uint64(1<<32)
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if f(mid) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+func addr4(i uint64) netip.Addr {
+	return netip.AddrFrom4([4]byte{byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)})
+}
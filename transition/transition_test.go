@@ -0,0 +1,180 @@
+package transition_test
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/gaissmai/iprange"
+	"github.com/gaissmai/iprange/transition"
+)
+
+func mustFromString(s string) iprange.IPRange {
+	r, err := iprange.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestNAT64(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		pfx  string
+		in   string
+		want []string
+	}{
+		{"64:ff9b::/96", "192.0.2.0/24", []string{"64:ff9b::c000:200/120"}},
+		{"64:ff9b::/96", "192.0.2.3-192.0.2.9", []string{"64:ff9b::c000:203-64:ff9b::c000:209"}},
+		{"64:ff9b::/96", "0.0.0.0/0", []string{"64:ff9b::/96"}},
+		{"2001:db8::/32", "192.0.2.0/24", []string{"2001:db8:c000:200::/56"}},
+		{"2001:db8::/32", "192.0.2.33", []string{"2001:db8:c000:221::/64"}},
+		{"2001:db8:100::/40", "192.0.2.0/24", []string{"2001:db8:1c0:2::/64"}},
+		{"2001:db8:100::/40", "192.0.2.33", []string{"2001:db8:1c0:2:21::/80"}},
+		{"2001:db8:122::/48", "192.0.2.33", []string{"2001:db8:122:c000:2:2100::/88"}},
+		{"2001:db8:122:300::/56", "192.0.2.33", []string{"2001:db8:122:3c0:0:221::/96"}},
+		{"2001:db8:122:344::/64", "192.0.2.33", []string{"2001:db8:122:344:c0:2:2100:0/104"}},
+		// across the u-octet, not contiguous
+		{"2001:db8:100::/40", "192.0.2.255-192.0.3.0", []string{"2001:db8:1c0:2:ff::/80", "2001:db8:1c0:3::/80"}},
+		// contiguous for /32
+		{"2001:db8::/32", "192.0.2.255-192.0.3.0", []string{"2001:db8:c000:2ff::-2001:db8:c000:300:ffff:ffff:ffff:ffff"}},
+	}
+
+	for _, tt := range tests {
+		pfx := netip.MustParsePrefix(tt.pfx)
+		in := mustFromString(tt.in)
+
+		got, err := transition.NAT64(pfx, in)
+		if err != nil {
+			t.Fatalf("NAT64(%s, %s) failed: %v", tt.pfx, tt.in, err)
+		}
+
+		var want []iprange.IPRange
+		for _, s := range tt.want {
+			want = append(want, mustFromString(s))
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("NAT64(%s, %s), got %v, want %v", tt.pfx, tt.in, got, want)
+		}
+
+		// and back again
+		for _, r := range got {
+			back, err := transition.ExtractNAT64(pfx, r)
+			if err != nil {
+				t.Fatalf("ExtractNAT64(%s, %s) failed: %v", tt.pfx, r, err)
+			}
+			if rest := back.Remove([]iprange.IPRange{in}); rest != nil {
+				t.Errorf("ExtractNAT64(%s, %s), got %v, not within %v", tt.pfx, r, back, in)
+			}
+		}
+	}
+}
+
+func TestNAT64Errors(t *testing.T) {
+	t.Parallel()
+	in := mustFromString("192.0.2.0/24")
+
+	for _, s := range []string{"64:ff9b::/95", "64:ff9b::1/96", "10.0.0.0/8", "2001:db8:0:0:ff00::/96"} {
+		if _, err := transition.NAT64(netip.MustParsePrefix(s), in); err == nil {
+			t.Errorf("NAT64(%s, %s), expected error", s, in)
+		}
+	}
+
+	if _, err := transition.NAT64(transition.WellKnownPrefix, mustFromString("::1")); err == nil {
+		t.Errorf("NAT64 with IPv6 range, expected error")
+	}
+	if _, err := transition.ExtractNAT64(transition.WellKnownPrefix, in); err == nil {
+		t.Errorf("ExtractNAT64 with IPv4 range, expected error")
+	}
+	if _, err := transition.ExtractNAT64(transition.WellKnownPrefix, mustFromString("2001:db8::/32")); err == nil {
+		t.Errorf("ExtractNAT64 outside of prefix, expected error")
+	}
+	// only the u-octet, no embedded address
+	if _, err := transition.ExtractNAT64(netip.MustParsePrefix("2001:db8:100::/40"), mustFromString("2001:db8:1c0:2:100::/72")); err == nil {
+		t.Errorf("ExtractNAT64 with u-octet set, expected error")
+	}
+}
+
+func TestExtractNAT64(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		pfx  string
+		in   string
+		want string
+	}{
+		{"64:ff9b::/96", "64:ff9b::/96", "0.0.0.0/0"},
+		{"64:ff9b::/96", "64:ff9b::a00:1-64:ff9b::a00:5", "10.0.0.1-10.0.0.5"},
+		{"64:ff9b::/96", "::/0", "0.0.0.0/0"},
+		{"2001:db8::/32", "2001:db8:a00:1:8000::-2001:db8:a00:2::", "10.0.0.1-10.0.0.2"},
+		{"2001:db8:100::/40", "2001:db8:10a:0:1::-2001:db8:10a:1::", "10.0.0.1-10.0.1.0"},
+	}
+
+	for _, tt := range tests {
+		got, err := transition.ExtractNAT64(netip.MustParsePrefix(tt.pfx), mustFromString(tt.in))
+		if err != nil {
+			t.Fatalf("ExtractNAT64(%s, %s) failed: %v", tt.pfx, tt.in, err)
+		}
+		if want := mustFromString(tt.want); got != want {
+			t.Errorf("ExtractNAT64(%s, %s), got %v, want %v", tt.pfx, tt.in, got, want)
+		}
+	}
+}
+
+func TestSixToFour(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"192.0.2.0/24", "2002:c000:200::/40"},
+		{"192.0.2.1", "2002:c000:201::/48"},
+		{"10.0.0.1-10.0.0.2", "2002:a00:1::-2002:a00:2:ffff:ffff:ffff:ffff:ffff"},
+		{"0.0.0.0/0", "2002::/16"},
+	}
+
+	for _, tt := range tests {
+		in := mustFromString(tt.in)
+		got, err := transition.SixToFour(in)
+		if err != nil {
+			t.Fatalf("SixToFour(%s) failed: %v", tt.in, err)
+		}
+		if want := mustFromString(tt.want); got != want {
+			t.Errorf("SixToFour(%s), got %v, want %v", tt.in, got, want)
+		}
+
+		back, err := transition.ExtractSixToFour(got)
+		if err != nil {
+			t.Fatalf("ExtractSixToFour(%s) failed: %v", got, err)
+		}
+		if back != in {
+			t.Errorf("ExtractSixToFour(%s), got %v, want %v", got, back, in)
+		}
+	}
+
+	if _, err := transition.SixToFour(mustFromString("::1")); err == nil {
+		t.Errorf("SixToFour with IPv6 range, expected error")
+	}
+	if _, err := transition.ExtractSixToFour(mustFromString("2001:db8::/32")); err == nil {
+		t.Errorf("ExtractSixToFour outside of 2002::/16, expected error")
+	}
+}
+
+func TestTeredo(t *testing.T) {
+	t.Parallel()
+
+	// example of RFC 4380, server 65.54.227.120, client 192.0.2.45
+	a := netip.MustParseAddr("2001:0:4136:e378:8000:63bf:3fff:fdd2")
+	server, client, ok := transition.Teredo(a)
+	if !ok {
+		t.Fatalf("Teredo(%s), not ok", a)
+	}
+	if server != netip.MustParseAddr("65.54.227.120") || client != netip.MustParseAddr("192.0.2.45") {
+		t.Errorf("Teredo(%s), got %s %s, want 65.54.227.120 192.0.2.45", a, server, client)
+	}
+
+	for _, s := range []string{"2002::1", "192.0.2.45"} {
+		if _, _, ok := transition.Teredo(netip.MustParseAddr(s)); ok {
+			t.Errorf("Teredo(%s), got ok, want not ok", s)
+		}
+	}
+}